@@ -0,0 +1,780 @@
+package bingo
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// minDrawsToWin returns the fewest numbers out of available that need to be
+// drawn, in any order, to complete the board's easiest win pattern. It
+// returns -1 when no line can be completed with the available numbers.
+func minDrawsToWin(b board, available map[int]bool) int {
+	best := -1
+	dims := b.dimensions()
+lines:
+	for _, l := range winLines(dims) {
+		needed := map[int]bool{}
+		for _, cell := range l {
+			val, marked := b.At(cell[0], cell[1])
+			if marked {
+				continue
+			}
+			if !available[val] {
+				continue lines
+			}
+			needed[val] = true
+		}
+		if best < 0 || len(needed) < best {
+			best = len(needed)
+		}
+	}
+	return best
+}
+
+// minDrawsAllWin returns a set of numbers out of universe that makes every
+// board win, in an order they can be drawn. It is a greedy heuristic for what
+// is a set cover problem, so the set is small but not necessarily minimal:
+// each step picks the single line, over all boards yet to win, needing the
+// fewest new numbers. Boards that cannot win with the universe are ignored.
+func minDrawsAllWin(boards []board, universe []int) (draws []int) {
+	available := map[int]bool{}
+	for _, number := range universe {
+		available[number] = true
+	}
+	boards = cloneBoards(boards)
+	for {
+		var best []int
+		found := false
+		for _, b := range boards {
+			if boardWon(b) {
+				continue
+			}
+			for _, l := range winLines(b.dimensions()) {
+				if needed, ok := lineNeeds(b, l, available); ok && len(needed) > 0 && (!found || len(needed) < len(best)) {
+					best, found = needed, true
+				}
+			}
+		}
+		if !found {
+			return
+		}
+		for _, number := range best {
+			boards = markDrawnNumber(boards, number)
+			delete(available, number)
+		}
+		draws = append(draws, best...)
+	}
+}
+
+// lineNeeds returns the distinct numbers still to be drawn to complete the
+// line, in line order, and false if one of them is not available
+func lineNeeds(b board, l line, available map[int]bool) (needed []int, ok bool) {
+	seen := map[int]bool{}
+	for _, cell := range l {
+		val, marked := b.At(cell[0], cell[1])
+		if marked || seen[val] {
+			continue
+		}
+		if !available[val] {
+			return nil, false
+		}
+		seen[val] = true
+		needed = append(needed, val)
+	}
+	return needed, true
+}
+
+// winIndexStats returns the mean and population variance of the draw index
+// on which each board wins on its own, and the number of boards that never
+// win (DNF), which are left out of the mean and variance
+func winIndexStats(boards []board, numbers []int) (mean, variance float64, dnf int) {
+	indices := drawIndices(numbers)
+	var wins []float64
+	for _, b := range boards {
+		if draw := winDraw(b, indices); draw >= 0 {
+			wins = append(wins, float64(draw))
+		} else {
+			dnf++
+		}
+	}
+	if len(wins) == 0 {
+		return 0, 0, dnf
+	}
+	for _, draw := range wins {
+		mean += draw
+	}
+	mean /= float64(len(wins))
+	for _, draw := range wins {
+		variance += (draw - mean) * (draw - mean)
+	}
+	variance /= float64(len(wins))
+	return
+}
+
+// wastedMarksPerBoard returns, for each board in order, how many of its cells
+// are marked after the draw on which it wins, up to the end of the part 2
+// game: the last board's win, or the last draw if some board never wins.
+// Boards that never win waste nothing.
+func wastedMarksPerBoard(boards []board, numbers []int) []int {
+	indices := drawIndices(numbers)
+	wins := make([]int, len(boards))
+	end := -1
+	for i, b := range boards {
+		wins[i] = winDraw(b, indices)
+		end = max(end, wins[i])
+	}
+	if slices.Contains(wins, -1) {
+		end = len(numbers) - 1
+	}
+	wasted := make([]int, len(boards))
+	for i, b := range boards {
+		if wins[i] < 0 {
+			continue
+		}
+		for y, row := range b.numbers {
+			for x, val := range row {
+				draw, ok := indices[val]
+				if ok && !b.isFree(y, x) && draw > wins[i] && draw <= end {
+					wasted[i]++
+				}
+			}
+		}
+	}
+	return wasted
+}
+
+// datasetEntropy returns the Shannon entropy, in bits, of the distribution
+// of values over every cell of the boards. Repetitive boards score low; the
+// maximum is log2 of the number of cells, reached when no value repeats.
+func datasetEntropy(boards []board) float64 {
+	counts := map[int]int{}
+	cells := 0
+	for _, b := range boards {
+		for _, row := range b.numbers {
+			for _, val := range row {
+				counts[val]++
+				cells++
+			}
+		}
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(cells)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// columnOnlyWinners returns the input indices of the boards on which a
+// column completes strictly before any row, under the given draws. Boards
+// completing a row and a column on the same draw do not count.
+func columnOnlyWinners(boards []board, numbers []int) (indices []int) {
+	draws := drawIndices(numbers)
+	// firstDraw is the earliest completion among lines, or -1
+	firstDraw := func(b board, lines []line) int {
+		first := -1
+		for _, l := range lines {
+			if draw, ok := lineDraw(b, l, draws); ok && (first < 0 || draw < first) {
+				first = draw
+			}
+		}
+		return first
+	}
+	for _, b := range boards {
+		dims := b.dimensions()
+		row := firstDraw(b, rowLines(dims.rows, dims.cols))
+		col := firstDraw(b, columnLines(dims.rows, dims.cols))
+		if col >= 0 && (row < 0 || col < row) {
+			indices = append(indices, b.index)
+		}
+	}
+	return
+}
+
+// winnableBoards returns the boards that can win at all with the draws: the
+// numbers of enough of their lines are all drawn at some point
+func winnableBoards(boards []board, numbers []int) (winnable []board) {
+	indices := drawIndices(numbers)
+	for _, b := range boards {
+		if winDraw(b, indices) >= 0 {
+			winnable = append(winnable, b)
+		}
+	}
+	return
+}
+
+// validateUniverse reports every unmarked board number that never appears in
+// the draws, grouped by board index, or nil when all boards are clean
+func validateUniverse(boards []board, numbers []int) error {
+	indices := drawIndices(numbers)
+	var problems []string
+	for _, b := range boards {
+		var missing []string
+		for y, row := range b.numbers {
+			for x, val := range row {
+				if _, marked := b.At(y, x); marked {
+					continue
+				}
+				if _, ok := indices[val]; !ok {
+					missing = append(missing, fmt.Sprint(val))
+				}
+			}
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("board %d: %s", b.index, strings.Join(missing, ",")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("numbers never drawn: %s", strings.Join(problems, "; "))
+}
+
+// nextWinnerAfter returns the input index of the board that wins soonest
+// after the draw at afterIndex among those that have not won by then, with
+// the index of its winning draw. Ties go to the first board. It returns false
+// when no other board wins within the draws.
+func nextWinnerAfter(boards []board, numbers []int, afterIndex int) (boardIndex, drawIndex int, ok bool) {
+	indices := drawIndices(numbers)
+	for _, b := range boards {
+		draw := winDraw(b, indices)
+		if draw > afterIndex && (!ok || draw < drawIndex) {
+			boardIndex, drawIndex, ok = b.index, draw, true
+		}
+	}
+	return
+}
+
+// cellRef locates a cell on one of several boards
+type cellRef struct {
+	board, row, col int // board is the position in the boards slice
+}
+
+// numberPositions indexes every cell of the boards by the number it holds, so
+// a draw can be applied without scanning all cells. Free cells are left out.
+func numberPositions(boards []board) map[int][]cellRef {
+	positions := map[int][]cellRef{}
+	for i, b := range boards {
+		for y, row := range b.numbers {
+			for x, val := range row {
+				if !b.isFree(y, x) {
+					positions[val] = append(positions[val], cellRef{i, y, x})
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// marksPerDraw returns, for each draw index, how many cells over all boards
+// the draw newly marked, ignoring wins
+func marksPerDraw(boards []board, numbers []int) []int {
+	boards = cloneBoards(boards)
+	positions := numberPositions(boards)
+	marks := make([]int, len(numbers))
+	for draw, number := range numbers {
+		for _, cell := range positions[number] {
+			if marked := &boards[cell.board].marked[cell.row][cell.col]; !*marked {
+				*marked = true
+				marks[draw]++
+			}
+		}
+	}
+	return marks
+}
+
+// replayWithout plays part 1 again on fresh copies of every board except the
+// one with input index removeIndex, to see how the winner depends on it
+func replayWithout(boards []board, numbers []int, removeIndex int) GameResult {
+	var rest []board
+	for _, b := range boards {
+		if b.index != removeIndex {
+			rest = append(rest, b)
+		}
+	}
+	return playBingoBestChoice(cloneBoards(rest), numbers)
+}
+
+// targetWin plays the draws for the board with the given input index alone
+// and returns its own result, regardless of which board wins the game
+func targetWin(boards []board, numbers []int, target int) (result GameResult, err error) {
+	for _, b := range boards {
+		if b.index != target {
+			continue
+		}
+		draw, ok := boardWinIndex(b, numbers)
+		if !ok {
+			return result, nil
+		}
+		won := markFirstN([]board{b}, numbers, draw+1)[0]
+		sum := calcBoardScore(won)
+		factor := multiplier(numbers[:draw+1])
+		return GameResult{
+			Won:         true,
+			Board:       won,
+			Sum:         sum,
+			Score:       sum * factor,
+			Multiplier:  factor,
+			Draw:        draw,
+			Number:      numbers[draw],
+			MarkedCells: markedCells(won),
+		}, nil
+	}
+	return result, fmt.Errorf("no board with index %d among %d boards", target, len(boards))
+}
+
+// remainingPossibleLines counts the board's win patterns that can still be
+// completed: every unmarked cell of the line holds a number in remaining
+func remainingPossibleLines(b board, remaining map[int]bool) (possible int) {
+	dims := b.dimensions()
+lines:
+	for _, l := range winLines(dims) {
+		for _, cell := range l {
+			val, marked := b.At(cell[0], cell[1])
+			if !marked && !remaining[val] {
+				continue lines
+			}
+		}
+		possible++
+	}
+	return
+}
+
+// boardWinIndex returns the index of the draw on which the board first wins,
+// independent of any other board, and false if it never wins
+func boardWinIndex(b board, numbers []int) (index int, ok bool) {
+	index = winDraw(b, drawIndices(numbers))
+	return index, index >= 0
+}
+
+// markFirstN returns clones of the boards with the first n numbers drawn
+func markFirstN(boards []board, numbers []int, n int) []board {
+	boards = cloneBoards(boards)
+	if n > len(numbers) {
+		n = len(numbers)
+	}
+	for _, number := range numbers[:n] {
+		boards = markDrawnNumber(boards, number)
+	}
+	return boards
+}
+
+// scoresAtDraw returns every board's unmarked sum as if the game had been
+// paused after the first k draws, in the original board order
+func scoresAtDraw(boards []board, numbers []int, k int) []int {
+	scores := make([]int, len(boards))
+	for i, b := range markFirstN(boards, numbers, k) {
+		scores[i] = calcBoardScore(b)
+	}
+	return scores
+}
+
+// lineCompletionDraws marks the numbers on a copy of the board and returns,
+// for every row and column, the index of the draw that completed it or -1
+// if it was never completed
+func lineCompletionDraws(b board, numbers []int) (rows []int, cols []int) {
+	height, width := len(b.numbers), len(b.numbers[0])
+	rows = make([]int, height)
+	cols = make([]int, width)
+	for i := range rows {
+		rows[i] = -1
+	}
+	for i := range cols {
+		cols[i] = -1
+	}
+	marked := []board{b.clone()}
+	for draw, number := range numbers {
+		marked = markDrawnNumber(marked, number)
+		for y := range rows {
+			if rows[y] < 0 && rowMarked(marked[0], y) {
+				rows[y] = draw
+			}
+		}
+		for x := range cols {
+			if cols[x] < 0 && colMarked(marked[0], x) {
+				cols[x] = draw
+			}
+		}
+	}
+	return
+}
+
+// drawsUntilAllWin returns the index of the draw on which the last board
+// wins, and false if some boards never win with the given numbers
+func drawsUntilAllWin(boards []board, numbers []int) (int, bool) {
+	remaining := cloneBoards(boards)
+	if len(remaining) == 0 {
+		return -1, false
+	}
+	for draw, number := range numbers {
+		remaining = findNonWinningBoards(markDrawnNumber(remaining, number))
+		if len(remaining) == 0 {
+			return draw, true
+		}
+	}
+	return -1, false
+}
+
+// lineCounts returns the number of marked cells in every row and column
+func (b board) lineCounts() (rowCounts, colCounts []int) {
+	rowCounts = make([]int, len(b.marked))
+	colCounts = make([]int, len(b.marked[0]))
+	for y, row := range b.marked {
+		for x, marked := range row {
+			if marked {
+				rowCounts[y]++
+				colCounts[x]++
+			}
+		}
+	}
+	return
+}
+
+// mergeMarks combines the marks of two copies of the same board: a cell is
+// marked in the result if it is marked in either input
+func mergeMarks(a, b board) (board, error) {
+	if !sameNumbers(a, b) {
+		return board{}, fmt.Errorf("cannot merge marks of boards %d and %d: numbers differ", a.index, b.index)
+	}
+	merged := a.clone()
+	for y := range merged.marked {
+		for x := range merged.marked[y] {
+			merged.marked[y][x] = a.marked[y][x] || b.marked[y][x]
+		}
+	}
+	return merged, nil
+}
+
+// boardDistance returns the number of cell positions where the original
+// numbers of the two boards differ; 0 means the boards are duplicates. Cells
+// that exist on only one of two differently sized boards count as different.
+func boardDistance(a, b board) (distance int) {
+	da, db := a.dimensions(), b.dimensions()
+	for y := 0; y < max(da.rows, db.rows); y++ {
+		for x := 0; x < max(da.cols, db.cols); x++ {
+			inA := y < da.rows && x < da.cols
+			inB := y < db.rows && x < db.cols
+			if inA != inB || (inA && a.numbers[y][x] != b.numbers[y][x]) {
+				distance++
+			}
+		}
+	}
+	return
+}
+
+// boardOverlap returns the number of distinct original values that appear on
+// both boards
+func boardOverlap(a, b board) (overlap int) {
+	values := map[int]bool{}
+	for _, row := range a.numbers {
+		for _, val := range row {
+			values[val] = true
+		}
+	}
+	for _, row := range b.numbers {
+		for _, val := range row {
+			if values[val] {
+				overlap++
+				// count each shared value once
+				delete(values, val)
+			}
+		}
+	}
+	return
+}
+
+// maxOverlapBoards limits -overlap-matrix to inputs whose matrix stays readable
+const maxOverlapBoards = 20
+
+// printOverlapMatrix writes the pairwise boardOverlap of the boards as a
+// table indexed by board index
+func printOverlapMatrix(w io.Writer, boards []board) error {
+	if len(boards) > maxOverlapBoards {
+		return fmt.Errorf("overlap matrix supports at most %d boards, got %d", maxOverlapBoards, len(boards))
+	}
+	fmt.Fprintf(w, "%5s", "")
+	for _, b := range boards {
+		fmt.Fprintf(w, " %3d", b.index)
+	}
+	fmt.Fprintln(w)
+	for _, a := range boards {
+		fmt.Fprintf(w, "%5d", a.index)
+		for _, b := range boards {
+			fmt.Fprintf(w, " %3d", boardOverlap(a, b))
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// commonMarkedCoords returns the row,col coordinates, in row-major order, that
+// are marked on every board. It returns nil without boards.
+func commonMarkedCoords(boards []board) (coords [][2]int) {
+	if len(boards) == 0 {
+		return nil
+	}
+	for _, cell := range markedCells(boards[0]) {
+		common := true
+		for _, b := range boards[1:] {
+			y, x := cell[0], cell[1]
+			if y >= len(b.marked) || x >= len(b.marked[y]) || !b.marked[y][x] {
+				common = false
+				break
+			}
+		}
+		if common {
+			coords = append(coords, cell)
+		}
+	}
+	return
+}
+
+// openingStats summarizes the board states after the first draws of a game
+type openingStats struct {
+	draws          int // number of draws applied
+	markedCells    int // marked cells across all boards
+	boardsWithLine int // boards with at least one completed row or column
+}
+
+// firstNDrawsStats marks the first n draws on clones of the boards and
+// aggregates how far the field got, without looking for a winner
+func firstNDrawsStats(boards []board, numbers []int, n int) (stats openingStats) {
+	if n > len(numbers) {
+		n = len(numbers)
+	}
+	stats.draws = n
+	for _, b := range markFirstN(boards, numbers, n) {
+		stats.markedCells += b.markedCount()
+		rowCounts, colCounts := b.lineCounts()
+		completed := false
+		for _, count := range rowCounts {
+			completed = completed || count == len(colCounts)
+		}
+		for _, count := range colCounts {
+			completed = completed || count == len(rowCounts)
+		}
+		if completed {
+			stats.boardsWithLine++
+		}
+	}
+	return
+}
+
+// boardSum returns the sum of the board's original numbers, marked or not
+func boardSum(b board) (sum int) {
+	for _, row := range b.numbers {
+		for _, val := range row {
+			sum += val
+		}
+	}
+	return
+}
+
+// sumAnomaly is a board whose sum is far from the median board sum
+type sumAnomaly struct {
+	board     int
+	sum       int
+	deviation float64 // relative to the median, e.g. -0.5 for half of it
+}
+
+// sumAnomalies returns the boards whose sum deviates from the median board
+// sum by more than threshold, a fraction of the median. Corrupt data often
+// shows up as boards with unusually high or low totals.
+func sumAnomalies(boards []board, threshold float64) (anomalies []sumAnomaly) {
+	if len(boards) == 0 {
+		return nil
+	}
+	sums := make([]int, len(boards))
+	for i, b := range boards {
+		sums[i] = boardSum(b)
+	}
+	sorted := append([]int(nil), sums...)
+	sort.Ints(sorted)
+	median := float64(sorted[len(sorted)/2])
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[len(sorted)/2-1]+sorted[len(sorted)/2]) / 2
+	}
+	if median == 0 {
+		return nil
+	}
+	for i, sum := range sums {
+		deviation := (float64(sum) - median) / median
+		if math.Abs(deviation) > threshold {
+			anomalies = append(anomalies, sumAnomaly{boards[i].index, sum, deviation})
+		}
+	}
+	return
+}
+
+// NumberDanger is how close drawing a number would bring the field to a win:
+// the boards and lines it would complete
+type NumberDanger struct {
+	Number int `json:"number"`
+	Boards int `json:"boards"` // boards with a line needing only this number
+	Lines  int `json:"lines"`  // lines needing only this number, over all boards
+}
+
+// dangerRanking ranks the remaining numbers by how many boards that have not
+// won yet hold them on a line that is one draw away from completion, most
+// dangerous first. Numbers completing no line are left out.
+func dangerRanking(boards []board, remaining []int) []NumberDanger {
+	dangers := map[int]*NumberDanger{}
+	for _, number := range remaining {
+		dangers[number] = &NumberDanger{Number: number}
+	}
+	for _, b := range boards {
+		if boardWon(b) {
+			continue
+		}
+		completes := map[int]bool{}
+		for _, l := range winLines(b.dimensions()) {
+			unmarked, last := 0, 0
+			for _, cell := range l {
+				if val, marked := b.At(cell[0], cell[1]); !marked {
+					unmarked, last = unmarked+1, val
+				}
+			}
+			if d, ok := dangers[last]; ok && unmarked == 1 {
+				d.Lines++
+				completes[last] = true
+			}
+		}
+		for number := range completes {
+			dangers[number].Boards++
+		}
+	}
+	var ranking []NumberDanger
+	for _, d := range dangers {
+		if d.Lines > 0 {
+			ranking = append(ranking, *d)
+		}
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		a, b := ranking[i], ranking[j]
+		if a.Boards != b.Boards {
+			return a.Boards > b.Boards
+		}
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Number < b.Number
+	})
+	return ranking
+}
+
+// boardSymmetry maps a cell to its image under a symmetry of the board
+type boardSymmetry struct {
+	name       string
+	squareOnly bool
+	mapCell    func(y, x int, dims dimensions) (int, int)
+}
+
+var boardSymmetryChecks = []boardSymmetry{
+	{"transpose", true, func(y, x int, d dimensions) (int, int) { return x, y }},
+	{"anti-transpose", true, func(y, x int, d dimensions) (int, int) { return d.cols - 1 - x, d.rows - 1 - y }},
+	{"rot90", true, func(y, x int, d dimensions) (int, int) { return x, d.rows - 1 - y }},
+	{"rot180", false, func(y, x int, d dimensions) (int, int) { return d.rows - 1 - y, d.cols - 1 - x }},
+	{"flip-horizontal", false, func(y, x int, d dimensions) (int, int) { return y, d.cols - 1 - x }},
+	{"flip-vertical", false, func(y, x int, d dimensions) (int, int) { return d.rows - 1 - y, x }},
+}
+
+// boardSymmetries returns the names of the symmetries under which the board's
+// original numbers are unchanged
+func boardSymmetries(b board) (symmetries []string) {
+	dims := b.dimensions()
+symmetries:
+	for _, sym := range boardSymmetryChecks {
+		if sym.squareOnly && dims.rows != dims.cols {
+			continue
+		}
+		for y := range b.numbers {
+			for x := range b.numbers[y] {
+				y2, x2 := sym.mapCell(y, x, dims)
+				if b.numbers[y][x] != b.numbers[y2][x2] {
+					continue symmetries
+				}
+			}
+		}
+		symmetries = append(symmetries, sym.name)
+	}
+	return
+}
+
+// transformBoard returns a copy of b with the given dimensions where every
+// cell, including its mark, is taken from the source cell returned by from
+func transformBoard(b board, dims dimensions, from func(y, x int) (int, int)) board {
+	t := newBoard(dims)
+	t.index, t.name = b.index, b.name
+	for y := 0; y < dims.rows; y++ {
+		for x := 0; x < dims.cols; x++ {
+			sy, sx := from(y, x)
+			t.numbers[y][x] = b.numbers[sy][sx]
+			t.marked[y][x] = b.marked[sy][sx]
+			if b.isFree(sy, sx) {
+				t.setFree(y, x)
+			}
+		}
+	}
+	return t
+}
+
+// rotate90 returns the board rotated a quarter turn clockwise. A board that is
+// not square swaps its number of rows and columns.
+func rotate90(b board) board {
+	d := b.dimensions()
+	return transformBoard(b, dimensions{d.cols, d.rows}, func(y, x int) (int, int) { return d.rows - 1 - x, y })
+}
+
+// flipHorizontal returns the board mirrored left to right
+func flipHorizontal(b board) board {
+	d := b.dimensions()
+	return transformBoard(b, d, func(y, x int) (int, int) { return y, d.cols - 1 - x })
+}
+
+// flipVertical returns the board mirrored top to bottom
+func flipVertical(b board) board {
+	d := b.dimensions()
+	return transformBoard(b, d, func(y, x int) (int, int) { return d.rows - 1 - y, x })
+}
+
+// decisivePrefix returns the shortest prefix of the draws that still produces
+// the same part 1 winner, i.e. the draws up to and including the winning one.
+// Without a winner all draws are returned.
+func decisivePrefix(boards []board, numbers []int) []int {
+	result := playBingoBestChoice(cloneBoards(boards), numbers)
+	if !result.Won {
+		return numbers
+	}
+	return numbers[:result.Draw+1]
+}
+
+// expectedDrawsToWin estimates the average number of draws until the board wins
+// when the universe of possible numbers is drawn in a random order, averaged
+// over the given number of trials. It returns -1 when drawing the whole
+// universe does not win the board.
+func expectedDrawsToWin(b board, universe []int, trials int, rng *rand.Rand) float64 {
+	order := append([]int(nil), universe...)
+	total := 0
+	for trial := 0; trial < trials; trial++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		boards := []board{b.clone()}
+		draws := -1
+		for draw, number := range order {
+			boards = markDrawnNumber(boards, number)
+			if boardWon(boards[0]) {
+				draws = draw + 1
+				break
+			}
+		}
+		if draws < 0 {
+			return -1
+		}
+		total += draws
+	}
+	return float64(total) / float64(trials)
+}
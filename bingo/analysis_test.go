@@ -0,0 +1,647 @@
+package bingo
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// numberSet returns the numbers as a set
+func numberSet(numbers ...int) map[int]bool {
+	set := map[int]bool{}
+	for _, number := range numbers {
+		set[number] = true
+	}
+	return set
+}
+
+func TestMinDrawsToWin(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 5\n7 8 9\n")
+	tests := []struct {
+		name      string
+		marked    []int
+		available map[int]bool
+		want      int
+	}{
+		{"all available", nil, numberSet(1, 2, 3, 4, 5, 7, 8, 9), 2}, // 4,5
+		{"row 1 unavailable", nil, numberSet(1, 2, 3, 7, 8, 9), 3},   // row 0
+		{"column 0", nil, numberSet(1, 4, 7, 8), 3},
+		{"partly marked", []int{1, 7}, numberSet(4), 1},
+		{"none possible", nil, numberSet(1, 2, 8), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boards := []board{b.clone()}
+			for _, number := range tt.marked {
+				boards = markDrawnNumber(boards, number)
+			}
+			if got := minDrawsToWin(boards[0], tt.available); got != tt.want {
+				t.Errorf("minDrawsToWin() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoresAtDraw(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{0, []int{300, 324, 325}}, // full sums
+		{12, []int{163, 187, 188}},
+		{15, []int{124, 148, 149}},
+		{len(numbers), []int{0, 0, 0}}, // every number is drawn
+		{len(numbers) + 5, []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got := scoresAtDraw(boards, numbers, tt.k); !slices.Equal(got, tt.want) {
+			t.Errorf("scoresAtDraw(k=%d) = %v, want %v", tt.k, got, tt.want)
+		}
+	}
+	if got := scoresAtDraw(boards, numbers, 0); !slices.Equal(got, []int{300, 324, 325}) {
+		t.Errorf("scoresAtDraw marked the original boards: %v", got)
+	}
+}
+
+func TestLineCompletionDraws(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n")
+	rows, cols := lineCompletionDraws(b, []int{5, 4, 2, 8, 6, 1, 7})
+	// row 1 completes with 6 at index 4, column 1 with 8 at index 3, column
+	// 0 with 7 at index 6
+	if want := []int{-1, 4, -1}; !slices.Equal(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	if want := []int{6, 3, -1}; !slices.Equal(cols, want) {
+		t.Errorf("cols = %v, want %v", cols, want)
+	}
+	if _, marked := b.At(1, 1); marked {
+		t.Error("lineCompletionDraws marked the board it was given")
+	}
+
+	rows, cols = lineCompletionDraws(b, nil)
+	if !slices.Equal(rows, []int{-1, -1, -1}) || !slices.Equal(cols, []int{-1, -1, -1}) {
+		t.Errorf("no draws: rows %v, cols %v", rows, cols)
+	}
+}
+
+func TestDrawsUntilAllWin(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// the part 2 winner is the last to win, on the 15th draw
+	if draw, ok := drawsUntilAllWin(boards, numbers); draw != 14 || !ok {
+		t.Errorf("sample: drawsUntilAllWin() = %d, %t, want 14, true", draw, ok)
+	}
+
+	boards = []board{
+		parseBoard(t, "1 2\n3 4\n"),
+		parseBoard(t, "1 99\n98 3\n"), // no line without 98 or 99
+	}
+	if draw, ok := drawsUntilAllWin(boards, []int{1, 2, 3, 4}); draw != -1 || ok {
+		t.Errorf("never-winning board: drawsUntilAllWin() = %d, %t, want -1, false", draw, ok)
+	}
+	if draw, ok := drawsUntilAllWin(nil, []int{1, 2, 3, 4}); draw != -1 || ok {
+		t.Errorf("no boards: drawsUntilAllWin() = %d, %t, want -1, false", draw, ok)
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	lines := playLines(t, "-exhaust", input)
+	if !slices.Contains(lines, "exhaust: all 3 boards won by draw #15") {
+		t.Errorf("-exhaust printed:\n%v", lines)
+	}
+}
+
+func TestLineCounts(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n")
+	for _, number := range []int{1, 5, 6, 7} {
+		b = markDrawnNumber([]board{b}, number)[0]
+	}
+	rows, cols := b.lineCounts()
+	if want := []int{1, 2}; !slices.Equal(rows, want) {
+		t.Errorf("row counts = %v, want %v", rows, want)
+	}
+	if want := []int{1, 1, 1}; !slices.Equal(cols, want) {
+		t.Errorf("column counts = %v, want %v", cols, want)
+	}
+}
+
+func TestMergeMarks(t *testing.T) {
+	card := parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n")
+	a := markDrawnNumber([]board{card.clone()}, 1)[0]
+	a = markDrawnNumber([]board{a}, 5)[0]
+	b := markDrawnNumber([]board{card.clone()}, 5)[0]
+	b = markDrawnNumber([]board{b}, 9)[0]
+	merged, err := mergeMarks(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y, row := range merged.numbers {
+		for x, val := range row {
+			_, marked := merged.At(y, x)
+			if want := val == 1 || val == 5 || val == 9; marked != want {
+				t.Errorf("cell %d marked = %t, want %t", val, marked, want)
+			}
+		}
+	}
+	if rows, _ := merged.lineCounts(); !slices.Equal(rows, []int{1, 1, 1}) {
+		t.Errorf("merged row counts = %v", rows)
+	}
+	if _, marked := a.At(2, 2); marked {
+		t.Error("mergeMarks marked its input")
+	}
+
+	other := parseBoard(t, "1 2 3\n4 5 6\n7 8 10\n")
+	if _, err := mergeMarks(a, other); err == nil {
+		t.Error("merged boards with different numbers")
+	}
+}
+
+func TestFirstNDrawsStats(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	tests := []struct {
+		n    int
+		want openingStats
+	}{
+		// every board holds each of the first three numbers 7, 4 and 9
+		{3, openingStats{draws: 3, markedCells: 9, boardsWithLine: 0}},
+		{12, openingStats{draws: 12, markedCells: 36, boardsWithLine: 1}},
+		{100, openingStats{draws: 27, markedCells: 75, boardsWithLine: 3}},
+	}
+	for _, tt := range tests {
+		if got := firstNDrawsStats(boards, numbers, tt.n); got != tt.want {
+			t.Errorf("firstNDrawsStats(%d) = %+v, want %+v", tt.n, got, tt.want)
+		}
+	}
+	if marked := boards[0].markedCount(); marked != 0 {
+		t.Errorf("firstNDrawsStats marked %d cells of its input", marked)
+	}
+}
+
+func TestBoardSymmetries(t *testing.T) {
+	tests := []struct {
+		name, rows string
+		want       []string
+	}{
+		{"none", "1 2\n3 4\n", nil},
+		{"transpose", "1 2 3\n2 4 5\n3 5 6\n", []string{"transpose"}},
+		{"mirrored", "1 2 1\n3 4 3\n1 2 1\n", []string{"rot180", "flip-horizontal", "flip-vertical"}},
+		{"rot180 only", "1 2 3\n4 5 4\n3 2 1\n", []string{"rot180"}},
+		{"uniform", "7 7\n7 7\n", []string{"transpose", "anti-transpose", "rot90", "rot180", "flip-horizontal", "flip-vertical"}},
+		{"non-square", "1 2 1\n1 2 1\n", []string{"rot180", "flip-horizontal", "flip-vertical"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := boardSymmetries(parseBoard(t, tt.rows)); !slices.Equal(got, tt.want) {
+				t.Errorf("boardSymmetries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	input := writeFile(t, "input", "1,2\n\n1 2 3\n2 4 5\n3 5 6\n\n1 2 3\n4 5 6\n7 8 9\n")
+	equalLines(t, playLines(t, "analyze", "-size", "3", "-symmetry", input), []string{"board 0 symmetry: transpose"})
+}
+
+func TestDecisivePrefix(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	prefix := decisivePrefix(boards, numbers)
+	if !slices.Equal(prefix, numbers[:12]) {
+		t.Errorf("decisivePrefix() = %v, want the first 12 draws", prefix)
+	}
+	full := playBingoBestChoice(cloneBoards(boards), numbers)
+	short := playBingoBestChoice(cloneBoards(boards), prefix)
+	if short.Score != full.Score || short.Board.index != full.Board.index || short.Draw != full.Draw {
+		t.Errorf("prefix winner %v on draw %d, want %v on draw %d", short, short.Draw, full, full.Draw)
+	}
+	if shorter := playBingoBestChoice(cloneBoards(boards), prefix[:len(prefix)-1]); shorter.Won {
+		t.Errorf("a shorter prefix still wins: %v", shorter)
+	}
+
+	if got := decisivePrefix(boards, numbers[:5]); !slices.Equal(got, numbers[:5]) {
+		t.Errorf("without a winner, decisivePrefix() = %v, want all draws", got)
+	}
+}
+
+func TestExpectedDrawsToWin(t *testing.T) {
+	b := parseBoard(t, "1 2\n3 4\n")
+	universe := []int{1, 2, 3, 4}
+	// 4 of the 6 pairs of first draws complete a line, otherwise the third
+	// draw always does: 2*4/6 + 3*2/6 = 7/3
+	const want = 7.0 / 3
+	got := expectedDrawsToWin(b, universe, 10000, rand.New(rand.NewSource(1)))
+	if math.Abs(got-want) > 0.05 {
+		t.Errorf("expectedDrawsToWin() = %.3f, want %.3f ± 0.05", got, want)
+	}
+	if again := expectedDrawsToWin(b, universe, 10000, rand.New(rand.NewSource(1))); again != got {
+		t.Errorf("same seed estimated %.3f, then %.3f", got, again)
+	}
+	if got := expectedDrawsToWin(b, []int{1, 4}, 10, rand.New(rand.NewSource(1))); got != -1 {
+		t.Errorf("unwinnable universe: expectedDrawsToWin() = %.3f, want -1", got)
+	}
+}
+
+func TestCommonMarkedCoords(t *testing.T) {
+	// 5 sits at 1,1 on every board, 1 and 9 are marked in different places
+	boards := []board{
+		mark(parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), 1, 5, 9),
+		mark(parseBoard(t, "9 2 3\n4 5 6\n7 8 1\n"), 1, 5, 9),
+		mark(parseBoard(t, "7 8 9\n4 5 6\n1 2 3\n"), 5, 9),
+	}
+	if got, want := commonMarkedCoords(boards), [][2]int{{1, 1}}; !slices.Equal(got, want) {
+		t.Errorf("commonMarkedCoords() = %v, want %v", got, want)
+	}
+	// the first two boards share both marked corners as well
+	if got, want := commonMarkedCoords(boards[:2]), [][2]int{{0, 0}, {1, 1}, {2, 2}}; !slices.Equal(got, want) {
+		t.Errorf("first two boards: commonMarkedCoords() = %v, want %v", got, want)
+	}
+	if got := commonMarkedCoords([]board{parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), boards[1]}); len(got) != 0 {
+		t.Errorf("unmarked board: commonMarkedCoords() = %v, want none", got)
+	}
+	if got := commonMarkedCoords(nil); got != nil {
+		t.Errorf("no boards: commonMarkedCoords() = %v, want nil", got)
+	}
+}
+
+func TestBoardTransforms(t *testing.T) {
+	square := mark(parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), 1)
+	wide := parseBoard(t, "1 2 3\n4 5 6\n")
+	tests := []struct {
+		name      string
+		got       board
+		want      string
+		markedRow int
+		markedCol int
+	}{
+		{"rotate90", rotate90(square), "7 4 1\n8 5 2\n9 6 3\n", 0, 2},
+		{"rotate90 twice", rotate90(rotate90(square)), "9 8 7\n6 5 4\n3 2 1\n", 2, 2},
+		{"rotate90 four times", rotate90(rotate90(rotate90(rotate90(square)))), "1 2 3\n4 5 6\n7 8 9\n", 0, 0},
+		{"flipHorizontal", flipHorizontal(square), "3 2 1\n6 5 4\n9 8 7\n", 0, 2},
+		{"flipVertical", flipVertical(square), "7 8 9\n4 5 6\n1 2 3\n", 2, 0},
+		{"rotate90 non-square", rotate90(wide), "4 1\n5 2\n6 3\n", -1, -1},
+		{"flipHorizontal non-square", flipHorizontal(wide), "3 2 1\n6 5 4\n", -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if want := parseBoard(t, tt.want); !sameNumbers(tt.got, want) {
+				t.Errorf("got\n%swant\n%s", tt.got, want)
+			}
+			for y, row := range tt.got.marked {
+				for x, marked := range row {
+					if want := y == tt.markedRow && x == tt.markedCol; marked != want {
+						t.Errorf("cell %d,%d marked = %t, want %t", y, x, marked, want)
+					}
+				}
+			}
+		})
+	}
+	if _, marked := square.At(0, 0); !marked || square.numbers[0][2] != 3 {
+		t.Error("transforms modified their input")
+	}
+}
+
+func TestRemainingPossibleLines(t *testing.T) {
+	b := mark(parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), 1, 5)
+	// 9 can no longer be drawn, which rules out row 2 and column 2
+	remaining := numberSet(2, 3, 4, 6, 7, 8)
+	if got := remainingPossibleLines(b, remaining); got != 4 {
+		t.Errorf("remainingPossibleLines() = %d, want 4", got)
+	}
+	useWinOptions(t, WinOptions{Rows: true, Columns: true, Diagonals: true, Lines: 1})
+	// of the diagonals only 3,5,7 is left
+	if got := remainingPossibleLines(b, remaining); got != 5 {
+		t.Errorf("with diagonals, remainingPossibleLines() = %d, want 5", got)
+	}
+	if got := remainingPossibleLines(b, nil); got != 0 {
+		t.Errorf("nothing left to draw: remainingPossibleLines() = %d, want 0", got)
+	}
+}
+
+func TestBoardDistance(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n")
+	tests := []struct {
+		name, other string
+		want        int
+	}{
+		{"identical", "1 2 3\n4 5 6\n7 8 9\n", 0},
+		{"one-off", "1 2 3\n4 50 6\n7 8 9\n", 1},
+		{"same numbers moved", "2 1 3\n4 5 6\n7 8 9\n", 2},
+		{"completely different", "11 12 13\n14 15 16\n17 18 19\n", 9},
+		{"smaller", "1 2\n4 5\n", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := parseBoard(t, tt.other)
+			if got := boardDistance(b, other); got != tt.want {
+				t.Errorf("boardDistance() = %d, want %d", got, tt.want)
+			}
+			if got := boardDistance(other, b); got != tt.want {
+				t.Errorf("reversed, boardDistance() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if got := boardDistance(b, mark(b, 1, 5, 9)); got != 0 {
+		t.Errorf("marked copy: boardDistance() = %d, want 0", got)
+	}
+}
+
+func TestBoardWinIndex(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// each board on its own, regardless of the others
+	for i, want := range []int{13, 14, 11} {
+		if index, ok := boardWinIndex(boards[i], numbers); index != want || !ok {
+			t.Errorf("board %d: boardWinIndex() = %d, %t, want %d, true", i, index, ok, want)
+		}
+	}
+	never := parseBoard(t, "1 99\n98 3\n")
+	if index, ok := boardWinIndex(never, []int{1, 2, 3, 4}); ok {
+		t.Errorf("never-winning board: boardWinIndex() = %d, true", index)
+	}
+	if _, marked := boards[0].At(0, 0); marked {
+		t.Error("boardWinIndex marked its input")
+	}
+}
+
+func TestBoardOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1 2\n3 4\n", "4 3\n5 6\n", 2},
+		{"1 2\n3 4\n", "5 6\n7 8\n", 0},
+		{"1 1\n2 3\n", "1 9\n9 1\n", 1}, // repeated values count once
+		{"1 2\n3 4\n", "1 2\n3 4\n", 4},
+	}
+	for _, tt := range tests {
+		a, b := parseBoard(t, tt.a), parseBoard(t, tt.b)
+		if got := boardOverlap(a, b); got != tt.want {
+			t.Errorf("boardOverlap(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := boardOverlap(b, a); got != tt.want {
+			t.Errorf("boardOverlap(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+
+	_, boards := parseInput(t, sampleInput)
+	var out strings.Builder
+	if err := printOverlapMatrix(&out, boards); err != nil {
+		t.Fatal(err)
+	}
+	if want := "        0   1   2\n    0  25  24  24\n    1  24  25  24\n    2  24  24  25\n"; out.String() != want {
+		t.Errorf("printOverlapMatrix() =\n%s\nwant\n%s", out.String(), want)
+	}
+	if err := printOverlapMatrix(&out, make([]board, maxOverlapBoards+1)); err == nil {
+		t.Errorf("printed the matrix of %d boards", maxOverlapBoards+1)
+	}
+}
+
+func TestMinDrawsAllWin(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	draws := minDrawsAllWin(boards, numbers)
+	if len(draws) == 0 || len(draws) > 3*5 {
+		t.Fatalf("minDrawsAllWin() = %v, want at most one line per board", draws)
+	}
+	available := numberSet(numbers...)
+	played := cloneBoards(boards)
+	for _, number := range draws {
+		if !available[number] {
+			t.Errorf("draw %d is not in the universe", number)
+		}
+		played = markDrawnNumber(played, number)
+	}
+	for _, b := range played {
+		if !boardWon(b) {
+			t.Errorf("board %d has not won after %v", b.index, draws)
+		}
+	}
+
+	// a board needing a number outside the universe is ignored
+	if got := minDrawsAllWin([]board{parseBoard(t, "1 2\n3 4\n")}, []int{1}); got != nil {
+		t.Errorf("minDrawsAllWin() without a winnable line = %v, want none", got)
+	}
+}
+
+func TestDangerRanking(t *testing.T) {
+	boards := []board{
+		mark(parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), 2, 3, 4, 7), // 1 completes row 0 and column 0
+		mark(parseBoard(t, "1 5\n30 31\n"), 30),                  // 1 completes column 0
+		mark(parseBoard(t, "5 40\n41 42\n"), 41, 42),             // already won
+		mark(parseBoard(t, "5 50\n51 52\n"), 51),                 // 5 completes column 0
+	}
+	got := dangerRanking(boards, []int{1, 5, 8, 9, 20})
+	want := []NumberDanger{{Number: 1, Boards: 2, Lines: 3}, {Number: 5, Boards: 1, Lines: 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("dangerRanking() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSumAnomalies(t *testing.T) {
+	var boards []board
+	for i, rows := range []string{"1 2\n3 4\n", "2 2\n3 4\n", "40 30\n20 10\n", "1 3\n3 4\n", "2 3\n3 3\n"} {
+		b := parseBoard(t, rows)
+		b.index = i
+		boards = append(boards, b)
+	}
+	if got := boardSum(boards[2]); got != 100 {
+		t.Errorf("boardSum() = %d, want 100", got)
+	}
+	// the median sum is 11
+	got := sumAnomalies(boards, 0.5)
+	if len(got) != 1 || got[0].board != 2 || got[0].sum != 100 || math.Abs(got[0].deviation-89.0/11) > 1e-9 {
+		t.Errorf("sumAnomalies() = %+v, want only board 2", got)
+	}
+	if got := sumAnomalies(boards, 10); got != nil {
+		t.Errorf("sumAnomalies() over a high threshold = %+v, want none", got)
+	}
+	if got := sumAnomalies(nil, 0.5); got != nil {
+		t.Errorf("sumAnomalies() without boards = %+v, want none", got)
+	}
+}
+
+func TestTargetWin(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	tests := []struct {
+		target, draw, number, score int
+	}{
+		{0, 13, 16, 2192},
+		{1, 14, 13, 1924},
+		{2, 11, 24, 4512},
+	}
+	for _, tt := range tests {
+		got, err := targetWin(boards, numbers, tt.target)
+		if err != nil || !got.Won || got.Board.index != tt.target || got.Draw != tt.draw || got.Number != tt.number || got.Score != tt.score {
+			t.Errorf("targetWin(%d) = board %d, draw %d, number %d, score %d, %v, want draw %d, number %d, score %d",
+				tt.target, got.Board.index, got.Draw, got.Number, got.Score, err, tt.draw, tt.number, tt.score)
+		}
+	}
+	if got, err := targetWin(boards, numbers[:12], 1); err != nil || got.Won {
+		t.Errorf("targetWin(1) after 12 draws = %v, %v, want no win", got, err)
+	}
+	if _, err := targetWin(boards, numbers, 7); err == nil {
+		t.Error("targetWin(7) of 3 boards succeeded")
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	lines := playLines(t, "-target", "0", input)
+	if want := "target board 0: won on draw #14, number 16, score 2192"; !slices.Contains(lines, want) {
+		t.Errorf("-target 0 printed:\n%s\nwant %q", strings.Join(lines, "\n"), want)
+	}
+	// an unknown board fails before any result is printed
+	stdout, stderr, code := runMain(t, "-target", "3", input)
+	if code != 1 || stdout != "" || !strings.Contains(stderr, "invalid -target: no board with index 3 among 3 boards") {
+		t.Errorf("-target 3: exit status %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func TestWinIndexStats(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// the boards win on draw indices 13, 14 and 11; the fourth never does
+	boards = append(boards, parseBoard(t, grid(5, 5, 100)))
+	mean, variance, dnf := winIndexStats(boards, numbers)
+	if math.Abs(mean-38.0/3) > 1e-9 || math.Abs(variance-14.0/9) > 1e-9 || dnf != 1 {
+		t.Errorf("winIndexStats() = %g, %g, %d, want %g, %g, 1", mean, variance, dnf, 38.0/3, 14.0/9)
+	}
+	if mean, variance, dnf := winIndexStats(boards[3:], numbers); mean != 0 || variance != 0 || dnf != 1 {
+		t.Errorf("no winners: winIndexStats() = %g, %g, %d, want 0, 0, 1", mean, variance, dnf)
+	}
+}
+
+func TestColumnOnlyWinners(t *testing.T) {
+	var boards []board
+	for i, rows := range []string{
+		"1 2\n3 4\n", // column 0 on draw 1, row 0 on draw 2
+		"1 2\n5 6\n", // row 0 only
+		"1 3\n2 4\n", // row 0 on draw 1, column 0 on draw 2
+		"3 2\n1 4\n", // column 0 on draw 1
+		"1 2\n2 9\n", // row 0 and column 0 both on draw 2
+		"7 8\n9 6\n", // never wins
+	} {
+		b := parseBoard(t, rows)
+		b.index = i
+		boards = append(boards, b)
+	}
+	if got, want := columnOnlyWinners(boards, []int{1, 3, 2, 4}), []int{0, 3}; !slices.Equal(got, want) {
+		t.Errorf("columnOnlyWinners() = %v, want %v", got, want)
+	}
+}
+
+func TestNextWinnerAfter(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// a copy of board 0 ties with it, and the first board wins the tie
+	boards = append(boards, boards[0].clone())
+	boards[3].index = 3
+	tests := []struct {
+		after, board, draw int
+		ok                 bool
+	}{
+		{11, 0, 13, true}, // the runner-up to part 1
+		{12, 0, 13, true},
+		{13, 1, 14, true},
+		{14, 0, 0, false},
+		{-1, 2, 11, true},
+	}
+	for _, tt := range tests {
+		if board, draw, ok := nextWinnerAfter(boards, numbers, tt.after); board != tt.board || draw != tt.draw || ok != tt.ok {
+			t.Errorf("nextWinnerAfter(%d) = %d, %d, %t, want %d, %d, %t", tt.after, board, draw, ok, tt.board, tt.draw, tt.ok)
+		}
+	}
+
+	lines := playLines(t, "-next-winner", writeFile(t, "input", sampleInput))
+	if want := "next winner: board 0 on draw #14, number 16"; !slices.Contains(lines, want) {
+		t.Errorf("-next-winner printed:\n%s\nwant %q", strings.Join(lines, "\n"), want)
+	}
+}
+
+func TestFeasible(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	if got := winnableBoards(boards, numbers[:12]); len(got) != 1 || got[0].index != 2 {
+		t.Errorf("winnableBoards() after 12 draws = %d boards, want only board 2", len(got))
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-feasible", input), []string{"feasible: 3 of 3 boards can win"})
+	equalLines(t, playLines(t, "-feasible", "-draw-count", "14", input), []string{"feasible: 2 of 3 boards can win"})
+	_, stderr, code := runMain(t, "-feasible", "-draw-count", "11", input)
+	if want := "infeasible: no board can win with the 11 number draws"; code != 1 || !strings.Contains(stderr, want) {
+		t.Errorf("-feasible with 11 draws: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestMarksPerDraw(t *testing.T) {
+	boards := []board{parseBoard(t, "1 2\n3 3\n"), parseBoard(t, "3 4\n1 *\n")}
+	// 3 is on three cells, a repeated draw and 9 mark nothing, the free cell
+	// is never counted
+	numbers := []int{1, 3, 9, 1, 4, 2}
+	if got, want := marksPerDraw(boards, numbers), []int{2, 3, 0, 0, 1, 1}; !slices.Equal(got, want) {
+		t.Errorf("marksPerDraw() = %v, want %v", got, want)
+	}
+	if marked := boards[0].markedCount(); marked != 0 {
+		t.Errorf("marksPerDraw marked %d cells of its input", marked)
+	}
+	if got, want := numberPositions(boards)[3], []cellRef{{0, 1, 0}, {0, 1, 1}, {1, 0, 0}}; !slices.Equal(got, want) {
+		t.Errorf("positions of 3 = %v, want %v", got, want)
+	}
+}
+
+func TestReplayWithout(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// removing the winner, board 2, promotes board 0
+	if got := replayWithout(boards, numbers, 2); !got.Won || got.Board.index != 0 || got.Score != 2192 || got.Draw != 13 {
+		t.Errorf("replayWithout(2) = board %d, score %d, draw %d, want board 0, 2192, 13", got.Board.index, got.Score, got.Draw)
+	}
+	if got := replayWithout(boards, numbers, 1); got.Board.index != 2 || got.Score != 4512 {
+		t.Errorf("replayWithout(1) = board %d, score %d, want board 2, 4512", got.Board.index, got.Score)
+	}
+	if boards[2].markedCount() != 0 {
+		t.Error("replayWithout marked the boards it was given")
+	}
+
+	lines := playLines(t, "-without", "2", writeFile(t, "input", sampleInput))
+	if want := "without board 2: part1 result: 2192, board 0 on draw #14"; !slices.Contains(lines, want) {
+		t.Errorf("-without 2 printed:\n%s\nwant %q", strings.Join(lines, "\n"), want)
+	}
+}
+
+func TestValidateUniverse(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	if err := validateUniverse(boards, numbers); err != nil {
+		t.Errorf("sample: validateUniverse() = %v", err)
+	}
+
+	boards = append(boards, parseBoard(t, "1 99\n* 42\n"))
+	boards[3].index = 3
+	err := validateUniverse(boards, numbers)
+	if want := "numbers never drawn: board 3: 99,42"; err == nil || err.Error() != want {
+		t.Errorf("validateUniverse() = %v, want %q", err, want)
+	}
+}
+
+func TestDatasetEntropy(t *testing.T) {
+	uniform := []board{parseBoard(t, "1 2\n3 4\n"), parseBoard(t, "5 6\n7 8\n")}
+	skewed := []board{parseBoard(t, "1 1\n1 1\n"), parseBoard(t, "1 1\n2 2\n")}
+	// 8 distinct values; 6 ones and 2 twos
+	if got := datasetEntropy(uniform); math.Abs(got-3) > 1e-9 {
+		t.Errorf("uniform datasetEntropy() = %g, want 3", got)
+	}
+	want := -(0.75*math.Log2(0.75) + 0.25*math.Log2(0.25))
+	if got := datasetEntropy(skewed); math.Abs(got-want) > 1e-9 {
+		t.Errorf("skewed datasetEntropy() = %g, want %g", got, want)
+	}
+	if got := datasetEntropy(skewed[:1]); got != 0 {
+		t.Errorf("single-value datasetEntropy() = %g, want 0", got)
+	}
+}
+
+func TestWastedMarksPerBoard(t *testing.T) {
+	boards := []board{parseBoard(t, "1 2\n3 4\n"), parseBoard(t, "5 6\n3 7\n")}
+	numbers := []int{1, 2, 3, 5, 6, 4}
+	// board 0 wins on draw 1 and marks 3 before board 1 ends the game with
+	// column 0 on draw 3; 6 and 4 come after that
+	if got, want := wastedMarksPerBoard(boards, numbers), []int{1, 0}; !slices.Equal(got, want) {
+		t.Errorf("wastedMarksPerBoard() = %v, want %v", got, want)
+	}
+	// with a board that never wins the game runs to the last draw
+	boards = append(boards, parseBoard(t, "8 9\n10 11\n"))
+	if got, want := wastedMarksPerBoard(boards, numbers), []int{2, 1, 0}; !slices.Equal(got, want) {
+		t.Errorf("with a losing board: wastedMarksPerBoard() = %v, want %v", got, want)
+	}
+}
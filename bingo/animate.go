@@ -0,0 +1,43 @@
+package bingo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ansiClear moves the cursor home and clears the screen
+const ansiClear = "\x1b[H\x1b[2J"
+
+// renderFrame renders the state of every board after a draw as one animation
+// frame, without the escape codes that clear the screen
+func renderFrame(draw, number int, boards []board, opts renderOptions) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "draw #%02d: %d\n", draw+1, number)
+	for _, b := range boards {
+		if boardWon(b) {
+			fmt.Fprintf(&sb, "\nboard %d (won):\n", b.index)
+		} else {
+			fmt.Fprintf(&sb, "\nboard %d:\n", b.index)
+		}
+		sb.WriteString(b.format(opts))
+	}
+	return sb.String()
+}
+
+// animate plays the boards draw by draw, redrawing every board after each
+// draw and pausing for delay between frames. It stops after the first win
+// unless all is set, in which case it runs until every board has won.
+func animate(w io.Writer, boards []board, numbers []int, opts renderOptions, delay time.Duration, all bool) {
+	boards = cloneBoards(boards)
+	for draw, number := range numbers {
+		boards = markDrawnNumber(boards, number)
+		fmt.Fprint(w, ansiClear+renderFrame(draw, number, boards, opts))
+		winners := len(findWinningBoards(boards))
+		if (!all && winners > 0) || winners == len(boards) {
+			return
+		}
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,56 @@
+package bingo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFrame(t *testing.T) {
+	boards := markDrawnNumber([]board{
+		parseBoard(t, "1 2\n3 4\n"),
+		parseBoard(t, "5 1\n7 8\n"),
+	}, 1)
+	boards[1].index = 1
+	boards = markDrawnNumber(boards, 2)
+	want := `draw #02: 2
+
+board 0 (won):
+[1],[2]
+  3,  4
+
+board 1:
+  5,[1]
+  7,  8
+`
+	if got := renderFrame(1, 2, boards, renderOptions{}); got != want {
+		t.Errorf("renderFrame() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAnimate(t *testing.T) {
+	boards := []board{parseBoard(t, "1 2\n3 4\n"), parseBoard(t, "5 1\n7 8\n")}
+	boards[1].index = 1
+	numbers := []int{1, 2, 5, 7, 9}
+	tests := []struct {
+		all    bool
+		frames int
+	}{
+		{false, 2}, // board 0 wins on the second draw
+		{true, 3},  // board 1 on the third
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		animate(&out, boards, numbers, renderOptions{}, 0, tt.all)
+		frames := strings.Split(out.String(), ansiClear)[1:]
+		if len(frames) != tt.frames {
+			t.Errorf("all %t: %d frames, want %d", tt.all, len(frames), tt.frames)
+			continue
+		}
+		if last := frames[len(frames)-1]; !strings.HasPrefix(last, renderFrame(tt.frames-1, numbers[tt.frames-1], nil, renderOptions{})) {
+			t.Errorf("all %t: last frame is\n%s", tt.all, last)
+		}
+	}
+	if _, marked := boards[0].At(0, 0); marked {
+		t.Error("animate marked the boards it was given")
+	}
+}
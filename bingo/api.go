@@ -0,0 +1,86 @@
+// Package bingo plays Advent of Code 2021 day 4, giant squid bingo: parsing
+// puzzle inputs, marking number draws and scoring the first and last boards
+// to win. Main runs the aoc4 command line tool on top of it.
+package bingo
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Board is a bingo board: a grid of numbers and which of them have been
+// drawn. Boards returned by ParseInput are unmarked.
+type Board = board
+
+// Size returns the number of rows and columns of the board
+func (b board) Size() (rows, cols int) {
+	dims := b.dimensions()
+	return dims.rows, dims.cols
+}
+
+// Index returns the position of the board in its input
+func (b board) Index() int {
+	return b.index
+}
+
+// ParseInput parses a text puzzle input in a single pass: an optional
+// "dim RxC" header, a comma-separated number draws line and blank-separated
+// boards of whitespace-separated numbers, 5x5 unless the header says
+// otherwise. The last board needs no blank line after it. A board with the
+// wrong number of rows or columns, or a stray line before the draws, is an
+// error.
+func ParseInput(r io.Reader) (numbers []int, boards []Board, err error) {
+	scanner, dims, err := textInput(r, boardSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if numbers, err = scanNumberDraws(scanner, ",", true); err != nil {
+		return nil, nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, nil, errors.New("no number draws found")
+	}
+	bs := newBoardScanner(scanner, "", dims)
+	bs.strict = true
+	for {
+		b, ok := bs.next()
+		if !ok {
+			break
+		}
+		boards = append(boards, b)
+	}
+	if err = bs.Err(); err != nil {
+		return nil, nil, err
+	}
+	return numbers, boards, nil
+}
+
+// PlayFirstWin plays part 1 by the rules of opts: the draws are marked until a
+// board wins. The boards are not modified.
+func PlayFirstWin(boards []Board, numbers []int, opts Options) GameResult {
+	result, _ := opts.rules().playFirstWin(context.Background(), cloneBoards(boards), numbers)
+	return result
+}
+
+// PlayLastWin plays part 2 by the rules of opts: the draws are marked until
+// the last board wins. The boards are not modified.
+func PlayLastWin(boards []Board, numbers []int, opts Options) GameResult {
+	result, _ := opts.rules().playLastWin(context.Background(), cloneBoards(boards), numbers)
+	return result
+}
+
+// PlayBoth plays both parts by the rules of opts in a single pass over the
+// draws and returns the scores of the first and the last board to win, 0 for
+// a part without a winner. The boards are not modified.
+func PlayBoth(boards []Board, numbers []int, opts Options) (firstScore, lastScore int) {
+	first, last := opts.rules().playBoth(cloneBoards(boards), numbers)
+	return first.Score, last.Score
+}
+
+// Score returns the sum of the board's unmarked numbers, or marked ones with
+// opts.ScoreMarked, which a winning board's score multiplies by the factor of
+// opts.Multiplier
+func Score(b Board, opts Options) int {
+	return opts.rules().scoreWith(b, nil)
+}
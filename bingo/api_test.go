@@ -0,0 +1,125 @@
+package bingo_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/lukassup/aoc4/bingo"
+)
+
+const input = `7,4,9,5,11,17,23,2,0,14,21,24,10,16,13,6,15,25,12,22,18,20,8,19,3,26,1
+
+22 13 17 11  0
+ 8  2 23  4 24
+21  9 14 16  7
+ 6 10  3 18  5
+ 1 12 20 15 19
+
+ 3 15  0  2 22
+ 9 18 13 17  5
+19  8  7 25 23
+20 11 10 24  4
+14 21 16 12  6
+
+14 21 17 24  4
+10 16 15  9 19
+18  8 23 26 20
+22 11 13  6  5
+ 2  0 12  3  7
+`
+
+func TestParseInput(t *testing.T) {
+	numbers, boards, err := bingo.ParseInput(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(numbers) != 27 || numbers[0] != 7 || numbers[26] != 1 {
+		t.Errorf("draws = %v", numbers)
+	}
+	if len(boards) != 3 {
+		t.Fatalf("parsed %d boards, want 3", len(boards))
+	}
+	b := boards[1]
+	if rows, cols := b.Size(); rows != 5 || cols != 5 || b.Index() != 1 {
+		t.Errorf("board 1 is %dx%d at index %d", rows, cols, b.Index())
+	}
+	var row []int
+	for x := 0; x < 5; x++ {
+		val, marked := b.At(2, x)
+		if marked {
+			t.Errorf("parsed board has cell 2,%d marked", x)
+		}
+		row = append(row, val)
+	}
+	if want := []int{19, 8, 7, 25, 23}; !slices.Equal(row, want) {
+		t.Errorf("board 1 row 2 = %v, want %v", row, want)
+	}
+	if got := bingo.Score(b, bingo.Options{}); got != 324 {
+		t.Errorf("Score() of an unmarked board = %d, want the sum 324", got)
+	}
+
+	if got := bingo.PlayFirstWin(boards, numbers, bingo.Options{}); got.Score != 4512 {
+		t.Errorf("PlayFirstWin() = %v, want 4512", got)
+	}
+	if got := bingo.PlayLastWin(boards, numbers, bingo.Options{}); got.Score != 1924 {
+		t.Errorf("PlayLastWin() = %v, want 1924", got)
+	}
+	if got := bingo.PlayFirstWin(boards, numbers, bingo.Options{Multiplier: "count-drawn"}); got.Score != 188*12 {
+		t.Errorf("PlayFirstWin() counting the draws = %v, want %d", got, 188*12)
+	}
+	if _, marked := boards[2].At(0, 0); marked {
+		t.Error("playing marked the parsed boards")
+	}
+}
+
+func TestParseInputReader(t *testing.T) {
+	// a byte at a time, without a newline after the last board
+	r := iotest.OneByteReader(strings.NewReader(strings.TrimSuffix(input, "\n")))
+	numbers, boards, err := bingo.ParseInput(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(numbers) != 27 || len(boards) != 3 {
+		t.Fatalf("parsed %d draws and %d boards, want 27 and 3", len(numbers), len(boards))
+	}
+	if val, _ := boards[2].At(4, 4); val != 7 {
+		t.Errorf("last cell = %d, want 7", val)
+	}
+
+	for _, tt := range []struct{ input, err string }{
+		{"dim 2x2\n1,2\n\n1 2\n3 4\n\n5,6\n", `board line 5: expected 2 numbers, got 1`},
+		{"dim 2x2\n1,2\n\n1 2\n3 4\n\n5 6\n", "board line 5: board 1 ends after 1 of 2 rows"},
+		{"", "no number draws found"},
+		{"\nnotes\n1,2\n", `draws line 2: unexpected "notes" before the number draws`},
+	} {
+		if _, _, err := bingo.ParseInput(strings.NewReader(tt.input)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseInput(%q) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+	if _, _, err := bingo.ParseInput(iotest.ErrReader(iotest.ErrTimeout)); !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("ParseInput() of a failing reader: error = %v, want %v", err, iotest.ErrTimeout)
+	}
+}
+
+func TestPlayBoth(t *testing.T) {
+	numbers, boards, err := bingo.ParseInput(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{len(numbers), 14, 11, 0} {
+		draws := numbers[:n]
+		first, last := bingo.PlayBoth(boards, draws, bingo.Options{})
+		if want := bingo.PlayFirstWin(boards, draws, bingo.Options{}).Score; first != want {
+			t.Errorf("PlayBoth() of %d draws: first = %d, PlayFirstWin() = %d", n, first, want)
+		}
+		if want := bingo.PlayLastWin(boards, draws, bingo.Options{}).Score; last != want {
+			t.Errorf("PlayBoth() of %d draws: last = %d, PlayLastWin() = %d", n, last, want)
+		}
+	}
+	if first, last := bingo.PlayBoth(boards, numbers, bingo.Options{}); first != 4512 || last != 1924 {
+		t.Errorf("PlayBoth() = %d, %d, want 4512, 1924", first, last)
+	}
+}
@@ -0,0 +1,1430 @@
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fatalError is the panic value raised by check, so callers can recover it
+// and tell it apart from other panics
+type fatalError struct {
+	err error
+}
+
+func (e fatalError) Error() string {
+	return e.err.Error()
+}
+
+func (e fatalError) Unwrap() error {
+	return e.err
+}
+
+func check(e error) {
+	if e != nil {
+		panic(fatalError{e})
+	}
+}
+
+// recoverFatal stores an error raised by check into *err and lets any other
+// panic through. It must be deferred.
+func recoverFatal(err *error) {
+	if r := recover(); r != nil {
+		fatal, ok := r.(fatalError)
+		if !ok {
+			panic(r)
+		}
+		*err = fatal
+	}
+}
+
+// timingCollector records the durations reported by timeit per phase name,
+// keeping phases in the order they were first seen
+type timingCollector struct {
+	mu     sync.Mutex
+	order  []string
+	phases map[string][]time.Duration
+}
+
+var timings = timingCollector{phases: map[string][]time.Duration{}}
+
+// traceDraws enables recording the time spent on every draw of a game
+var traceDraws bool
+
+// drawTrace records per-draw durations (marking and win checking) while
+// traceDraws is enabled
+type drawTrace struct {
+	enabled   bool
+	start     time.Time
+	durations []time.Duration
+}
+
+func newDrawTrace() *drawTrace {
+	return &drawTrace{enabled: traceDraws}
+}
+
+func (t *drawTrace) begin() {
+	if t.enabled {
+		t.start = time.Now()
+	}
+}
+
+func (t *drawTrace) end() {
+	if t.enabled {
+		t.durations = append(t.durations, time.Since(t.start))
+	}
+}
+
+// allocReport is the heap allocation done between two runtime.MemStats
+// snapshots
+type allocReport struct {
+	objects uint64 // heap objects allocated
+	bytes   uint64 // heap bytes allocated, including freed ones
+}
+
+// measureAllocs returns the heap allocations made while running fn. Reading
+// runtime.MemStats stops the world briefly, so it is only done on request.
+func measureAllocs(fn func()) allocReport {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return allocReport{after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc}
+}
+
+func (r allocReport) String() string {
+	return fmt.Sprintf("allocs: %d objects, %d bytes", r.objects, r.bytes)
+}
+
+func (c *timingCollector) record(name string, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.phases[name]; !ok {
+		c.order = append(c.order, name)
+	}
+	c.phases[name] = append(c.phases[name], elapsed)
+}
+
+// summarize writes the min/median/max durations of every phase to w. It is
+// asked for with -repeat, so unlike the per-call timings it does not depend
+// on the log level.
+func (c *timingCollector) summarize(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.order {
+		durations := append([]time.Duration(nil), c.phases[name]...)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		fmt.Fprintf(w, "timing %s: %d runs, min %v, median %v, max %v\n", name, len(durations),
+			durations[0], durations[len(durations)/2], durations[len(durations)-1])
+	}
+}
+
+// timedPhases maps the names passed to timeit to the phase they belong to
+var timedPhases = map[string]string{
+	"parseNumberDraws":        "parse",
+	"parseNumberBoards":       "parse",
+	"parseJSONInput":          "parse",
+	"playBingoBestChoice":     "part1",
+	"streamFirstWinner":       "part1",
+	"playFirstWinIncremental": "part1",
+	"playBingoWorstChoice":    "part2",
+}
+
+// profilePhase restricts timeit to one phase of timedPhases, or "all"
+var profilePhase = "all"
+
+// collectTimings makes timeit record durations for timings even when they
+// are not logged
+var collectTimings bool
+
+// timeit records and logs at debug level the time since start. With
+// -profile-phase it only times that phase and prints to stderr whatever the
+// log level. Otherwise it does nothing, not even measure, unless debug logging
+// or collectTimings is on.
+func timeit(start time.Time, name string) {
+	if profilePhase != "all" {
+		if timedPhases[name] != profilePhase {
+			return
+		}
+	} else if !collectTimings && !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	elapsed := time.Since(start)
+	timings.record(name, elapsed)
+	if profilePhase != "all" {
+		fmt.Fprintf(os.Stderr, "timing %s: %v\n", name, elapsed)
+		return
+	}
+	slog.Debug("duration", "func", name, "elapsed", elapsed)
+}
+
+// setupLogging routes diagnostics to stderr at the given level so that
+// results printed on stdout stay clean
+func setupLogging(level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid -log-level %q: %w", level, err)
+	}
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: lvl})
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// boardSize is the default number of rows and columns of a board
+const boardSize = 5
+
+const boardLabelPrefix = "Board:"
+
+// freeToken marks a wildcard board cell, which counts as marked from the
+// start and holds 0. "FREE" is accepted too, in any case.
+const freeToken = "*"
+
+func isFreeToken(field string) bool {
+	return field == freeToken || strings.EqualFold(field, "FREE")
+}
+
+// dimensionsHeaderPrefix starts an optional first input line such as "dim 7x7"
+const dimensionsHeaderPrefix = "dim "
+
+// dimensions holds the number of rows and columns of every board in a game
+type dimensions struct {
+	rows, cols int
+}
+
+func (d dimensions) String() string {
+	return fmt.Sprintf("%dx%d", d.rows, d.cols)
+}
+
+// parseDimensions parses a "ROWSxCOLS" size such as "7x7"
+func parseDimensions(size string) (dims dimensions, err error) {
+	rows, cols, ok := strings.Cut(strings.TrimSpace(size), "x")
+	if !ok {
+		return dims, fmt.Errorf("invalid board dimensions %q: expected ROWSxCOLS", size)
+	}
+	if dims.rows, err = strconv.Atoi(rows); err != nil {
+		return dims, fmt.Errorf("invalid board dimensions %q: %w", size, err)
+	}
+	if dims.cols, err = strconv.Atoi(cols); err != nil {
+		return dims, fmt.Errorf("invalid board dimensions %q: %w", size, err)
+	}
+	if dims.rows < 1 || dims.cols < 1 {
+		return dims, fmt.Errorf("invalid board dimensions %q: must be at least 1x1", size)
+	}
+	return dims, nil
+}
+
+// readDimensionsHeader consumes a "dim ROWSxCOLS" header if the input starts
+// with one. It reports false and leaves the input untouched otherwise.
+func readDimensionsHeader(r *bufio.Reader) (dims dimensions, ok bool, err error) {
+	prefix, _ := r.Peek(len(dimensionsHeaderPrefix))
+	if string(prefix) != dimensionsHeaderPrefix {
+		return dims, false, nil
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return dims, false, err
+	}
+	dims, err = parseDimensions(strings.TrimSpace(strings.TrimPrefix(line, dimensionsHeaderPrefix)))
+	return dims, err == nil, err
+}
+
+// board keeps the original numbers and a separate mask of drawn (marked)
+// cells so that marking never destroys a value
+type board struct {
+	index   int    // position of the board in the input
+	name    string // optional label, empty for unnamed boards
+	numbers [][]int
+	marked  [][]bool
+	free    [][]bool // wildcard cells that are always marked, nil without any
+}
+
+func newBoard(dims dimensions) board {
+	b := board{
+		numbers: make([][]int, dims.rows),
+		marked:  make([][]bool, dims.rows),
+	}
+	for y := range b.numbers {
+		b.numbers[y] = make([]int, dims.cols)
+		b.marked[y] = make([]bool, dims.cols)
+	}
+	return b
+}
+
+// newBoardFromNumbers creates an unmarked board from rows of numbers, which
+// must all have the same length
+func newBoardFromNumbers(numbers [][]int) (board, error) {
+	if len(numbers) == 0 || len(numbers[0]) == 0 {
+		return board{}, errors.New("board has no numbers")
+	}
+	b := newBoard(dimensions{len(numbers), len(numbers[0])})
+	for y, row := range numbers {
+		if len(row) != len(numbers[0]) {
+			return board{}, fmt.Errorf("board row %d has %d numbers, expected %d", y, len(row), len(numbers[0]))
+		}
+		copy(b.numbers[y], row)
+	}
+	return b, nil
+}
+
+func (b board) dimensions() dimensions {
+	return dimensions{len(b.numbers), len(b.numbers[0])}
+}
+
+// clone returns a deep copy of the board, so marking the copy leaves the
+// original untouched
+func (b board) clone() board {
+	c := newBoard(b.dimensions())
+	c.index, c.name = b.index, b.name
+	for y := range b.numbers {
+		copy(c.numbers[y], b.numbers[y])
+		copy(c.marked[y], b.marked[y])
+	}
+	for y := range b.free {
+		for x, free := range b.free[y] {
+			if free {
+				c.setFree(y, x)
+			}
+		}
+	}
+	return c
+}
+
+// setFree turns the cell into a wildcard that counts as marked from the start
+func (b *board) setFree(y, x int) {
+	if b.free == nil {
+		b.free = make([][]bool, len(b.numbers))
+		for i := range b.free {
+			b.free[i] = make([]bool, len(b.numbers[i]))
+		}
+	}
+	b.free[y][x] = true
+	b.marked[y][x] = true
+}
+
+// isFree reports whether the cell is a wildcard
+func (b board) isFree(y, x int) bool {
+	return b.free != nil && b.free[y][x]
+}
+
+// sameNumbers reports whether two boards hold the same numbers in the same
+// cells, ignoring marks
+func sameNumbers(a, b board) bool {
+	if a.dimensions() != b.dimensions() {
+		return false
+	}
+	for y := range a.numbers {
+		for x := range a.numbers[y] {
+			if a.numbers[y][x] != b.numbers[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checksum returns an FNV-1a hash of the board's original numbers in
+// row-major order, so identical boards share a checksum
+func (b board) checksum() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, row := range b.numbers {
+		for _, val := range row {
+			binary.LittleEndian.PutUint64(buf[:], uint64(val))
+			h.Write(buf[:])
+		}
+	}
+	return h.Sum64()
+}
+
+// At returns the number at the given cell and whether it has been drawn
+func (b board) At(row, col int) (value int, marked bool) {
+	return b.numbers[row][col], b.marked[row][col]
+}
+
+// find returns the coordinate of the first cell, in row-major order, holding
+// value, whether or not it has been drawn
+func (b board) find(value int) (row, col int, ok bool) {
+	for y, r := range b.numbers {
+		for x, val := range r {
+			if val == value {
+				return y, x, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// renderOptions controls how a board is rendered as text. It only affects
+// the rendering, never the game logic.
+type renderOptions struct {
+	transpose bool // print columns as rows
+	box       bool // draw borders around and between cells
+	ascii     bool // use +, - and | instead of box-drawing characters
+	numbered  bool // label rows and columns with their indices
+	tsv       bool // tab-separated cells, marked numbers suffixed with *
+	color     bool // highlight marked numbers with ANSI escapes instead of -1
+}
+
+// ansiMarked and ansiReset wrap a highlighted marked number
+const (
+	ansiMarked = "\x1b[1;7m"
+	ansiReset  = "\x1b[0m"
+)
+
+// pad right-aligns the rendered cell to width, highlighting the number of a
+// marked cell when color is enabled. The escapes are added after padding so
+// they do not count towards the width.
+func (opts renderOptions) pad(cell string, width int, marked bool) string {
+	padding := strings.Repeat(" ", max(0, width-len(cell)))
+	if marked && opts.color {
+		return padding + ansiMarked + cell + ansiReset
+	}
+	return padding + cell
+}
+
+// boxChars are the characters used to draw box borders, indexed by row
+// position (top, middle, bottom) and then column position (left, middle,
+// right), followed by the horizontal and vertical lines
+type boxChars struct {
+	corners          [3][3]string
+	horizontal, wall string
+}
+
+var (
+	unicodeBox = boxChars{
+		corners:    [3][3]string{{"┌", "┬", "┐"}, {"├", "┼", "┤"}, {"└", "┴", "┘"}},
+		horizontal: "─",
+		wall:       "│",
+	}
+	asciiBox = boxChars{
+		corners:    [3][3]string{{"+", "+", "+"}, {"+", "+", "+"}, {"+", "+", "+"}},
+		horizontal: "-",
+		wall:       "|",
+	}
+)
+
+// displayAt returns the number and mark of the cell displayed at row y and
+// column x, which differ from the board's own coordinates when transposed
+func (b board) displayAt(opts renderOptions, y, x int) (value int, marked bool) {
+	if opts.transpose {
+		y, x = x, y
+	}
+	return b.At(y, x)
+}
+
+// cells returns the rendered text of every cell in display order; wildcards
+// are rendered as *, drawn numbers in brackets, or suffixed with * for TSV,
+// unless color highlights them
+func (b board) cells(opts renderOptions) [][]string {
+	rows, cols := len(b.numbers), len(b.numbers[0])
+	if opts.transpose {
+		rows, cols = cols, rows
+	}
+	cells := make([][]string, rows)
+	for y := range cells {
+		cells[y] = make([]string, cols)
+		for x := range cells[y] {
+			val, marked := b.displayAt(opts, y, x)
+			row, col := y, x
+			if opts.transpose {
+				row, col = x, y
+			}
+			switch {
+			case b.isFree(row, col):
+				cells[y][x] = freeToken
+			case marked && opts.tsv:
+				cells[y][x] = strconv.Itoa(val) + "*"
+			case marked && !opts.color:
+				cells[y][x] = "[" + strconv.Itoa(val) + "]"
+			default:
+				cells[y][x] = strconv.Itoa(val)
+			}
+		}
+	}
+	return cells
+}
+
+// cellWidth returns the width every column needs to fit its widest cell and
+// column index, but at least minWidth
+func cellWidth(cells [][]string, minWidth int) int {
+	width := max(minWidth, len(strconv.Itoa(len(cells[0])-1)))
+	for _, row := range cells {
+		for _, cell := range row {
+			width = max(width, len(cell))
+		}
+	}
+	return width
+}
+
+// format renders the board one row per line, optionally boxed and with row
+// and column indices
+func (b board) format(opts renderOptions) string {
+	cells := b.cells(opts)
+	if opts.tsv {
+		var sb strings.Builder
+		for _, row := range cells {
+			sb.WriteString(strings.Join(row, "\t") + "\n")
+		}
+		return sb.String()
+	}
+	if opts.box {
+		return b.formatBox(cells, opts)
+	}
+	width := cellWidth(cells, 3)
+	labelWidth := len(strconv.Itoa(len(cells) - 1))
+	var sb strings.Builder
+	if opts.numbered {
+		// the header skips the row labels, and a space stands in for each comma
+		fmt.Fprintf(&sb, "%*s  ", labelWidth, "")
+		for x := range cells[0] {
+			if x > 0 {
+				sb.WriteString(" ")
+			}
+			fmt.Fprintf(&sb, "%*d", width, x)
+		}
+		sb.WriteString("\n")
+	}
+	for y, row := range cells {
+		if opts.numbered {
+			fmt.Fprintf(&sb, "%*d: ", labelWidth, y)
+		}
+		for x, cell := range row {
+			if x > 0 {
+				sb.WriteString(",")
+			}
+			_, marked := b.displayAt(opts, y, x)
+			sb.WriteString(opts.pad(cell, width, marked))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatBox renders cells inside a grid of borders, with every column as wide
+// as the widest cell
+func (b board) formatBox(cells [][]string, opts renderOptions) string {
+	chars := unicodeBox
+	if opts.ascii {
+		chars = asciiBox
+	}
+	width := cellWidth(cells, 0)
+	cols := len(cells[0])
+	// indent is the room left of the box for row labels
+	indent := ""
+	if opts.numbered {
+		indent = strings.Repeat(" ", len(strconv.Itoa(len(cells)-1))+1)
+	}
+	var sb strings.Builder
+	border := func(pos int) {
+		sb.WriteString(indent)
+		for x := 0; x < cols; x++ {
+			if x == 0 {
+				sb.WriteString(chars.corners[pos][0])
+			} else {
+				sb.WriteString(chars.corners[pos][1])
+			}
+			sb.WriteString(strings.Repeat(chars.horizontal, width+2))
+		}
+		sb.WriteString(chars.corners[pos][2] + "\n")
+	}
+	if opts.numbered {
+		sb.WriteString(indent)
+		for x := 0; x < cols; x++ {
+			if x > 0 {
+				sb.WriteString(" ")
+			}
+			fmt.Fprintf(&sb, "  %*d", width, x)
+		}
+		sb.WriteString("\n")
+	}
+	border(0)
+	for y, row := range cells {
+		if y > 0 {
+			border(1)
+		}
+		if opts.numbered {
+			fmt.Fprintf(&sb, "%*d ", len(indent)-1, y)
+		}
+		for x, cell := range row {
+			_, marked := b.displayAt(opts, y, x)
+			sb.WriteString(chars.wall + " " + opts.pad(cell, width, marked) + " ")
+		}
+		sb.WriteString(chars.wall + "\n")
+	}
+	border(2)
+	return sb.String()
+}
+
+func (b board) String() string {
+	return b.format(renderOptions{})
+}
+
+// Marshal renders the board's original numbers in the native whitespace
+// separated input format, preceded by its label if it has a name and with
+// wildcards as *, so that parseNumberBoards reads back the same board
+func (b board) Marshal() string {
+	var sb strings.Builder
+	if b.name != "" {
+		sb.WriteString(boardLabelPrefix + " " + b.name + "\n")
+	}
+	for y, row := range b.numbers {
+		cells := make([]string, len(row))
+		for x, val := range row {
+			if b.isFree(y, x) {
+				cells[x] = fmt.Sprintf("%2s", freeToken)
+			} else {
+				cells[x] = fmt.Sprintf("%2d", val)
+			}
+		}
+		sb.WriteString(strings.Join(cells, " ") + "\n")
+	}
+	return sb.String()
+}
+
+// writeNormalized writes the input in canonical text form: a "dim" header
+// unless the boards are the default size, the draws comma-separated, and every
+// board in Marshal form preceded by a single blank line
+func writeNormalized(w io.Writer, numbers []int, boards []board) error {
+	var sb strings.Builder
+	if len(boards) > 0 {
+		if dims := boards[0].dimensions(); dims != (dimensions{boardSize, boardSize}) {
+			sb.WriteString(dimensionsHeaderPrefix + dims.String() + "\n")
+		}
+	}
+	sb.WriteString(joinDraws(numbers) + "\n")
+	for _, b := range boards {
+		sb.WriteString("\n" + b.Marshal())
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func printBoard(board board, opts renderOptions) {
+	fmt.Print(board.format(opts))
+}
+
+// parseNumberDraws parses the first non-empty line containing delim as the
+// number draws. A whitespace delim takes the first non-empty line.
+func parseNumberDraws(scanner *bufio.Scanner, delim string) (numbers []int, err error) {
+	return scanNumberDraws(scanner, delim, false)
+}
+
+// scanNumberDraws parses the first line containing delim as the number draws.
+// Other lines before it are skipped, unless strict makes any non-empty one an
+// error.
+func scanNumberDraws(scanner *bufio.Scanner, delim string, strict bool) (numbers []int, err error) {
+	defer timeit(time.Now(), "parseNumberDraws")
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if strict && len(line) > 0 && !isWhitespace(delim) && !strings.Contains(line, delim) {
+			return nil, fmt.Errorf("draws line %d: unexpected %q before the number draws", lineNumber, line)
+		}
+		if len(line) > 0 && (isWhitespace(delim) || strings.Contains(line, delim)) {
+			for _, numstring := range splitFields(line, delim) {
+				number, err := strconv.Atoi(numstring)
+				if err != nil {
+					return nil, fmt.Errorf("draws line %d: invalid number %q in %q", lineNumber, numstring, line)
+				}
+				numbers = append(numbers, number)
+			}
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading number draws: %w", err)
+	}
+	if len(numbers) == 0 {
+		slog.Warn("no number draws line found")
+	}
+	return numbers, nil
+}
+
+// cutDrawsLine parses line n of the input, counting from 1, as the number
+// draws and blanks it out so the board parser skips it. Draws without delim
+// are split on whitespace.
+func cutDrawsLine(data []byte, n int, delim string) (numbers []int, rest []byte, err error) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if n > len(lines) || len(bytes.TrimSpace(lines[n-1])) == 0 {
+		return nil, nil, fmt.Errorf("draws line %d: no number draws found", n)
+	}
+	line := strings.TrimSpace(string(lines[n-1]))
+	if !strings.Contains(line, delim) {
+		delim = " "
+	}
+	if numbers, err = parseIntList(line, delim); err != nil {
+		return nil, nil, fmt.Errorf("draws line %d: %w", n, err)
+	}
+	lines[n-1] = []byte("\n")
+	return numbers, bytes.Join(lines, nil), nil
+}
+
+func isWhitespace(delim string) bool {
+	return strings.TrimSpace(delim) == ""
+}
+
+// splitFields splits a line into number fields. An empty or whitespace delim
+// splits on any run of whitespace, otherwise fields are split on delim and
+// trimmed.
+func splitFields(line string, delim string) []string {
+	if isWhitespace(delim) {
+		return strings.Fields(line)
+	}
+	fields := strings.Split(line, delim)
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// readNumberDraws parses the number draws line from a separate file
+func readNumberDraws(filename string, delim string) []int {
+	fd, err := openInput(filename)
+	check(err)
+	defer fd.Close()
+	numbers, err := parseNumberDraws(bufio.NewScanner(fd), delim)
+	if err != nil {
+		check(fmt.Errorf("%s: %w", filename, err))
+	}
+	return numbers
+}
+
+// parseIntList parses a delimited list of integers such as "3,7,12"
+func parseIntList(list string, delim string) (numbers []int, err error) {
+	for _, field := range splitFields(list, delim) {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return
+}
+
+func parseNumberBoards(scanner *bufio.Scanner, delim string, dims dimensions) (boards []board, err error) {
+	defer timeit(time.Now(), "parseNumberBoards")
+	boards = []board{}
+	bs := newBoardScanner(scanner, delim, dims)
+	for {
+		b, ok := bs.next()
+		if !ok {
+			return boards, bs.Err()
+		}
+		boards = append(boards, b)
+	}
+}
+
+// boardScanner reads boards one at a time, so that callers can process
+// inputs without holding every board in memory
+type boardScanner struct {
+	scanner    *bufio.Scanner
+	delim      string
+	dims       dimensions
+	boards     int // boards read so far
+	lineNumber int
+	err        error // first error, which ends the scan
+	// strict rejects comma-separated lines instead of skipping them as the
+	// draws line, for inputs whose draws line was already read
+	strict bool
+}
+
+func newBoardScanner(scanner *bufio.Scanner, delim string, dims dimensions) *boardScanner {
+	return &boardScanner{scanner: scanner, delim: delim, dims: dims}
+}
+
+// next returns the next complete board, or false at the end of the input or
+// on an error, which Err then returns
+func (s *boardScanner) next() (board, bool) {
+	if s.err != nil {
+		return board{}, false
+	}
+	var currentBoard board
+	var currentRow int = 0
+	var label string
+	for s.scanner.Scan() {
+		s.lineNumber++
+		line := strings.TrimSpace(s.scanner.Text())
+		if len(line) == 0 && currentRow > 0 {
+			s.err = fmt.Errorf("board line %d: board %d ends after %d of %d rows",
+				s.lineNumber, s.boards, currentRow, s.dims.rows)
+			return board{}, false
+		}
+		if len(line) > 0 {
+			// skip number draws line, unless boards are comma-delimited too
+			if !s.strict && s.delim != "," && strings.Contains(line, ",") {
+				continue
+			}
+			// an optional "Board: <name>" line labels the following board
+			if currentRow == 0 && strings.HasPrefix(line, boardLabelPrefix) {
+				label = strings.TrimSpace(strings.TrimPrefix(line, boardLabelPrefix))
+				continue
+			}
+			if currentRow == 0 {
+				currentBoard = newBoard(s.dims)
+				currentBoard.index, currentBoard.name = s.boards, label
+				label = ""
+			}
+			fields := splitFields(line, s.delim)
+			if len(fields) != s.dims.cols {
+				s.err = fmt.Errorf("board line %d: expected %d numbers, got %d: %q",
+					s.lineNumber, s.dims.cols, len(fields), line)
+				return board{}, false
+			}
+			for pos, numstring := range fields {
+				if isFreeToken(numstring) {
+					currentBoard.setFree(currentRow, pos)
+					continue
+				}
+				num, err := strconv.Atoi(numstring)
+				if err != nil {
+					s.err = fmt.Errorf("board line %d: invalid number %q in %q", s.lineNumber, numstring, line)
+					return board{}, false
+				}
+				currentBoard.numbers[currentRow][pos] = num
+			}
+			if currentRow < s.dims.rows-1 {
+				currentRow++
+			} else {
+				s.boards++
+				return currentBoard, true
+			}
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		s.err = fmt.Errorf("reading boards: %w", err)
+		return board{}, false
+	}
+	if currentRow != 0 {
+		s.err = fmt.Errorf("board line %d: board %d ends after %d of %d rows",
+			s.lineNumber, s.boards, currentRow, s.dims.rows)
+	}
+	return board{}, false
+}
+
+// Err returns the error that ended the scan, or nil at the end of the input
+func (s *boardScanner) Err() error {
+	return s.err
+}
+
+// IsValidInput reports whether r holds a well-formed puzzle input: an optional
+// dimensions header, a comma-separated draws line and at least one complete
+// whitespace-separated board. The error explains why an input is invalid.
+func IsValidInput(r io.Reader) (valid bool, err error) {
+	scanner, dims, err := textInput(r, boardSize)
+	if err != nil {
+		return false, err
+	}
+	numbers, err := parseNumberDraws(scanner, ",")
+	if err != nil {
+		return false, err
+	}
+	if len(numbers) == 0 {
+		return false, errors.New("no number draws found")
+	}
+	boards, err := parseNumberBoards(scanner, "", dims)
+	if err != nil {
+		return false, err
+	}
+	if len(boards) == 0 {
+		return false, errors.New("no complete boards found")
+	}
+	return true, nil
+}
+
+// jsonInput is the JSON form of a puzzle input
+type jsonInput struct {
+	Draws  []int     `json:"draws"`
+	Boards [][][]int `json:"boards"`
+}
+
+// parseJSONInput reads a puzzle input in its JSON form. All boards must have
+// the same dimensions.
+func parseJSONInput(r io.Reader) (numbers []int, boards []board, err error) {
+	defer timeit(time.Now(), "parseJSONInput")
+	var input jsonInput
+	if err = json.NewDecoder(r).Decode(&input); err != nil {
+		return nil, nil, fmt.Errorf("decoding JSON input: %w", err)
+	}
+	boards = make([]board, len(input.Boards))
+	for i, rows := range input.Boards {
+		if boards[i], err = newBoardFromNumbers(rows); err != nil {
+			return nil, nil, fmt.Errorf("board %d: %w", i, err)
+		}
+		boards[i].index = i
+		if i > 0 && boards[i].dimensions() != boards[0].dimensions() {
+			return nil, nil, fmt.Errorf("board %d is %v, expected %v", i, boards[i].dimensions(), boards[0].dimensions())
+		}
+	}
+	return input.Draws, boards, nil
+}
+
+// markedCount returns the number of marked cells on the board
+func (b board) markedCount() (count int) {
+	for _, row := range b.marked {
+		for _, marked := range row {
+			if marked {
+				count++
+			}
+		}
+	}
+	return
+}
+
+// markMask returns the marked cells as a bitmask with bit row*cols+col set
+// for every marked cell. Boards of more than 64 cells do not fit.
+func (b board) markMask() (uint64, error) {
+	dims := b.dimensions()
+	if dims.rows*dims.cols > 64 {
+		return 0, fmt.Errorf("board %d is %v, more than 64 cells do not fit a mask", b.index, dims)
+	}
+	var mask uint64
+	for y, row := range b.marked {
+		for x, marked := range row {
+			if marked {
+				mask |= 1 << (y*dims.cols + x)
+			}
+		}
+	}
+	return mask, nil
+}
+
+// markedCells returns the row,col coordinates of every marked cell in
+// row-major order
+func markedCells(b board) (cells [][2]int) {
+	for y, row := range b.marked {
+		for x, marked := range row {
+			if marked {
+				cells = append(cells, [2]int{y, x})
+			}
+		}
+	}
+	return
+}
+
+// ForEachBoard calls fn for every board in order and stops at the first
+// error fn returns, which is passed on to the caller
+func ForEachBoard(boards []board, fn func(i int, b board) error) error {
+	for i, b := range boards {
+		if err := fn(i, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cloneBoards(boards []board) []board {
+	clones := make([]board, len(boards))
+	for i, b := range boards {
+		clones[i] = b.clone()
+	}
+	return clones
+}
+
+// markDrawnNumber marks every cell holding number, on large board counts in
+// parallel. Marking is idempotent: a number drawn again changes nothing, and
+// a number on no board is ignored.
+func markDrawnNumber(boards []board, number int) []board {
+	mark := func(lo, hi int) {
+		for b := lo; b < hi; b++ {
+			for y := range boards[b].numbers {
+				for x := range boards[b].numbers[y] {
+					if boards[b].numbers[y][x] == number {
+						boards[b].marked[y][x] = true
+					}
+				}
+			}
+		}
+	}
+	if len(boards) < parallelThreshold {
+		mark(0, len(boards))
+	} else {
+		forEachChunk(len(boards), mark)
+	}
+	return boards
+}
+
+// boardWon reports whether the board has completed enough of the win
+// patterns selected by winOptions
+func boardWon(board board) bool {
+	return currentRules().boardWon(board)
+}
+
+// boardWon reports whether the board has completed enough of the win
+// patterns of the rules
+func (r rules) boardWon(b board) bool {
+	return hasCompletedLines(b, winLinesFor(b.dimensions(), r.win), r.win.Lines)
+}
+
+func rowMarked(b board, y int) bool {
+	for _, marked := range b.marked[y] {
+		if !marked {
+			return false
+		}
+	}
+	return true
+}
+
+func colMarked(b board, x int) bool {
+	for y := range b.marked {
+		if !b.marked[y][x] {
+			return false
+		}
+	}
+	return true
+}
+
+func findWinningBoards(boards []board) []board {
+	return currentRules().winningBoards(boards)
+}
+
+func (r rules) winningBoards(boards []board) (winningBoards []board) {
+	for i, won := range r.wonBoards(boards) {
+		if won {
+			winningBoards = append(winningBoards, boards[i])
+		}
+	}
+	return
+}
+
+func calcBoardScore(board board) (score int) {
+	return currentRules().scoreWith(board, nil)
+}
+
+// weightMatrix holds a per-cell multiplier used for weighted scoring, with
+// the same dimensions as the board. A nil matrix weighs every cell as 1.
+type weightMatrix [][]int
+
+// scoreMarked makes scores sum the drawn numbers on a board instead of the
+// undrawn ones. Free cells were never drawn and count towards neither.
+var scoreMarked bool
+
+// scoredCell reports whether the cell counts towards the board's score
+func (r rules) scoredCell(b board, y, x int) bool {
+	if r.scoreMarked {
+		return b.marked[y][x] && !b.isFree(y, x)
+	}
+	return !b.marked[y][x]
+}
+
+// scoreWith sums all numbers on the board that have not been drawn yet, or
+// have with scoreMarked, each multiplied by the weight of its cell
+func scoreWith(board board, weights weightMatrix) int {
+	return currentRules().scoreWith(board, weights)
+}
+
+func (r rules) scoreWith(board board, weights weightMatrix) (score int) {
+	for y, row := range board.numbers {
+		for x, val := range row {
+			if !r.scoredCell(board, y, x) {
+				continue
+			}
+			if weights != nil {
+				val *= weights[y][x]
+			}
+			score += val
+		}
+	}
+	return
+}
+
+// rowScores returns the scored sum of each row of the board, unmarked unless
+// scoreMarked is set
+func rowScores(b board) []int {
+	r := currentRules()
+	sums := make([]int, len(b.numbers))
+	for y, row := range b.numbers {
+		for x, val := range row {
+			if r.scoredCell(b, y, x) {
+				sums[y] += val
+			}
+		}
+	}
+	return sums
+}
+
+// colScores returns the scored sum of each column of the board, like
+// rowScores
+func colScores(b board) []int {
+	r := currentRules()
+	sums := make([]int, b.dimensions().cols)
+	for y, row := range b.numbers {
+		for x, val := range row {
+			if r.scoredCell(b, y, x) {
+				sums[x] += val
+			}
+		}
+	}
+	return sums
+}
+
+func findHighestScoringBoard(boards []board) board {
+	return currentRules().highestScoring(boards)
+}
+
+func (r rules) highestScoring(boards []board) (bestBoard board) {
+	// in case there is more than one board, pick the better one
+	bestScore := 0
+	for i, board := range boards {
+		score := r.scoreWith(board, nil)
+		if i == 0 || score > bestScore {
+			bestScore = score
+			bestBoard = board
+		}
+	}
+	return
+}
+
+func (r rules) lowestScoring(boards []board) (bestBoard board) {
+	for i, board := range boards {
+		if i == 0 || r.scoreWith(board, nil) < r.scoreWith(bestBoard, nil) {
+			bestBoard = board
+		}
+	}
+	return
+}
+
+// lastWinnerTiebreaks pick the reported board when several boards win
+// together on part 2's final draw. AoC inputs never have such a tie, but
+// variants may.
+var lastWinnerTiebreaks = map[string]func(rules, []board) board{
+	"highest":     rules.highestScoring,
+	"lowest":      rules.lowestScoring,
+	"first-index": func(_ rules, boards []board) board { return boards[0] },
+}
+
+// lastTiebreak is the key into lastWinnerTiebreaks used by part 2
+var lastTiebreak = "highest"
+
+// scoreMultiplier is a way of computing the factor the winning board's
+// unmarked sum is multiplied by, from the numbers drawn so far
+type scoreMultiplier struct {
+	label  string // name of the factor in explanations
+	factor func(drawn []int) int
+}
+
+var scoreMultipliers = map[string]scoreMultiplier{
+	"last": {"lastNumber", func(drawn []int) int { return drawn[len(drawn)-1] }},
+	"sum-drawn": {"sumDrawn", func(drawn []int) (sum int) {
+		for _, number := range drawn {
+			sum += number
+		}
+		return
+	}},
+	"count-drawn": {"countDrawn", func(drawn []int) int { return len(drawn) }},
+}
+
+// multiplierMode is the key into scoreMultipliers used for every score
+var multiplierMode = "last"
+
+// multiplier returns the score factor after the given draws
+func multiplier(drawn []int) int {
+	return currentRules().factor(drawn)
+}
+
+// rules are the settings that decide when a board wins and what it scores.
+// The command line sets them once from its flags in winOptions,
+// multiplierMode, scoreMarked and lastTiebreak and plays with currentRules;
+// the exported API passes its Options down instead.
+type rules struct {
+	win         WinOptions
+	multiplier  string // key of scoreMultipliers
+	scoreMarked bool
+	tiebreak    string // key of lastWinnerTiebreaks
+}
+
+// currentRules returns the rules selected by the command line flags
+func currentRules() rules {
+	return rules{winOptions, multiplierMode, scoreMarked, lastTiebreak}
+}
+
+// factor returns the score factor after the given draws
+func (r rules) factor(drawn []int) int {
+	return scoreMultipliers[r.multiplier].factor(drawn)
+}
+
+// lastWinner picks the board reported by part 2 among the boards winning
+// together on the final draw
+func (r rules) lastWinner(boards []board) board {
+	return lastWinnerTiebreaks[r.tiebreak](r, boards)
+}
+
+// GameResult describes the outcome of a single game. Won is false when no
+// board completed a line within the drawn numbers.
+type GameResult struct {
+	Won    bool
+	Score  int
+	Sum    int // sum of the unmarked numbers on the winning board, or marked with scoreMarked
+	Draw   int // index into the number draws
+	Number int // number that completed the winning line
+	// Multiplier is the factor Sum was multiplied by, the last number unless
+	// another multiplier mode is selected
+	Multiplier int
+	Board      board
+	// Winners lists every board that completed a line on the winning draw
+	Winners []board
+	// Trace holds per-draw durations when draw tracing is enabled
+	Trace []time.Duration
+	// MarkedCells lists the row,col coordinates marked on the winning board
+	MarkedCells [][2]int
+}
+
+func (r GameResult) String() string {
+	if !r.Won {
+		return "no winning board"
+	}
+	if r.Board.name != "" {
+		return fmt.Sprintf("%d (%s)", r.Score, r.Board.name)
+	}
+	return fmt.Sprintf("%d", r.Score)
+}
+
+// explain spells out how the score was computed, including how the unmarked
+// sum is spread over the winning board's rows and columns
+func (r GameResult) explain() string {
+	join := func(sums []int) string {
+		fields := make([]string, len(sums))
+		for i, sum := range sums {
+			fields[i] = strconv.Itoa(sum)
+		}
+		return strings.Join(fields, " ")
+	}
+	return fmt.Sprintf("score = sum(%d) * %s(%d) = %d, row sums: %s, column sums: %s",
+		r.Sum, scoreMultipliers[multiplierMode].label, r.Multiplier, r.Score,
+		join(rowScores(r.Board)), join(colScores(r.Board)))
+}
+
+// tsv formats the result as a tab-separated row of part, score, winning
+// number and draw index. Without a winner only the part is filled in.
+func (r GameResult) tsv(part int) string {
+	if !r.Won {
+		return fmt.Sprintf("%d\t\t\t", part)
+	}
+	return fmt.Sprintf("%d\t%d\t%d\t%d", part, r.Score, r.Number, r.Draw)
+}
+
+// summary formats both parts' results on one line as
+// "part1=SCORE(n=NUMBER,i=DRAW) part2=...", with "part2=none" for a part
+// without a winner
+func summary(results ...GameResult) string {
+	parts := make([]string, len(results))
+	for i, r := range results {
+		if !r.Won {
+			parts[i] = fmt.Sprintf("part%d=none", i+1)
+		} else {
+			parts[i] = fmt.Sprintf("part%d=%d(n=%d,i=%d)", i+1, r.Score, r.Number, r.Draw)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// interruptedError reports how far a game got before it was canceled
+type interruptedError struct {
+	draws     int // number of draws completed
+	remaining int // boards still in play
+	err       error
+}
+
+func (e *interruptedError) Error() string {
+	return fmt.Sprintf("interrupted after %d draws with %d boards remaining: %v",
+		e.draws, e.remaining, e.err)
+}
+
+func (e *interruptedError) Unwrap() error {
+	return e.err
+}
+
+// winResult builds the result of a game won by winner, one of the winners
+// completing a line on the draw at index draw
+func winResult(winner board, winners []board, numbers []int, draw int) GameResult {
+	return currentRules().winResult(winner, winners, numbers, draw)
+}
+
+func (r rules) winResult(winner board, winners []board, numbers []int, draw int) GameResult {
+	sum := r.scoreWith(winner, nil)
+	factor := r.factor(numbers[:draw+1])
+	return GameResult{
+		Won:         true,
+		Board:       winner.clone(),
+		Sum:         sum,
+		Score:       sum * factor,
+		Multiplier:  factor,
+		Draw:        draw,
+		Number:      numbers[draw],
+		Winners:     cloneBoards(winners),
+		MarkedCells: markedCells(winner),
+	}
+}
+
+// playBoth plays both parts in a single pass over the draws: part 1 ends on
+// the first win, and the game goes on with the boards that have not won
+// until the last of them wins together
+func playBoth(boards []board, numbers []int) (first, last GameResult) {
+	return currentRules().playBoth(boards, numbers)
+}
+
+func (r rules) playBoth(boards []board, numbers []int) (first, last GameResult) {
+	defer timeit(time.Now(), "playBoth")
+	for draw, number := range numbers {
+		boards = markDrawnNumber(boards, number)
+		var winners, remaining []board
+		for i, won := range r.wonBoards(boards) {
+			if won {
+				winners = append(winners, boards[i])
+			} else {
+				remaining = append(remaining, boards[i])
+			}
+		}
+		if !first.Won && len(winners) > 0 {
+			first = r.winResult(r.highestScoring(winners), winners, numbers, draw)
+		}
+		if len(remaining) == 0 && len(winners) > 0 {
+			last = r.winResult(r.lastWinner(winners), winners, numbers, draw)
+			return
+		}
+		boards = remaining
+	}
+	return
+}
+
+func playBingoBestChoice(boards []board, numbers []int) GameResult {
+	result, _ := playBingoBestChoiceContext(context.Background(), boards, numbers)
+	return result
+}
+
+// playBingoBestChoiceContext plays part 1 until the first board wins or ctx is
+// canceled, in which case an *interruptedError is returned
+func playBingoBestChoiceContext(ctx context.Context, boards []board, numbers []int) (result GameResult, err error) {
+	return currentRules().playFirstWin(ctx, boards, numbers)
+}
+
+func (r rules) playFirstWin(ctx context.Context, boards []board, numbers []int) (result GameResult, err error) {
+	defer timeit(time.Now(), "playBingoBestChoice")
+	trace := newDrawTrace()
+	defer func() { result.Trace = trace.durations }()
+	for draw, currentNumber := range numbers {
+		if err = waitForDraw(ctx, draw); err != nil {
+			return result, &interruptedError{draws: draw, remaining: len(boards), err: err}
+		}
+		trace.begin()
+		boards = markDrawnNumber(boards, currentNumber)
+		winningBoards := r.winningBoards(boards)
+		trace.end()
+		slog.Debug("draw", "draw", draw+1, "number", currentNumber)
+		if len(winningBoards) > 0 {
+			slog.Info("winning board(s) found",
+				"draw", draw+1, "number", currentNumber, "boards", len(winningBoards))
+			result = r.winResult(r.highestScoring(winningBoards), winningBoards, numbers, draw)
+			break
+		}
+	}
+	return
+}
+
+func findNonWinningBoards(boards []board) []board {
+	return currentRules().nonWinningBoards(boards)
+}
+
+func (r rules) nonWinningBoards(boards []board) (nonWinningBoards []board) {
+	for i, won := range r.wonBoards(boards) {
+		if !won {
+			nonWinningBoards = append(nonWinningBoards, boards[i])
+		}
+	}
+	return
+}
+
+func playBingoWorstChoice(boards []board, numbers []int) GameResult {
+	result, _ := playBingoWorstChoiceContext(context.Background(), boards, numbers)
+	return result
+}
+
+// playBingoWorstChoiceContext plays part 2 until the last board wins or ctx
+// is canceled, in which case an *interruptedError is returned
+func playBingoWorstChoiceContext(ctx context.Context, boards []board, numbers []int) (result GameResult, err error) {
+	return currentRules().playLastWin(ctx, boards, numbers)
+}
+
+func (r rules) playLastWin(ctx context.Context, boards []board, numbers []int) (result GameResult, err error) {
+	defer timeit(time.Now(), "playBingoWorstChoice")
+	// select the board to win LAST
+	// filter all winning boards until the remaining ones all win together
+	trace := newDrawTrace()
+	defer func() { result.Trace = trace.durations }()
+	for draw, currentNumber := range numbers {
+		if err = waitForDraw(ctx, draw); err != nil {
+			return result, &interruptedError{draws: draw, remaining: len(boards), err: err}
+		}
+		trace.begin()
+		boards = markDrawnNumber(boards, currentNumber)
+		slog.Debug("draw", "draw", draw+1, "number", currentNumber, "boards", len(boards))
+		// no longer need to iterate over boards that have already won, unless
+		// every remaining board wins on this draw; without boards nobody wins
+		remaining := r.nonWinningBoards(boards)
+		trace.end()
+		if len(remaining) == 0 && len(boards) > 0 {
+			slog.Info("last winning board(s) found",
+				"draw", draw+1, "number", currentNumber, "boards", len(boards))
+			result = r.winResult(r.lastWinner(boards), boards, numbers, draw)
+			break
+		}
+		boards = remaining
+	}
+	return
+}
+
+// selectBoards keeps only the boards at the given input indices, preserving
+// their relative order and original indices
+func selectBoards(boards []board, indices []int) ([]board, error) {
+	selected := map[int]bool{}
+	for _, i := range indices {
+		if i < 0 || i >= len(boards) {
+			return nil, fmt.Errorf("board index %d out of range [0, %d)", i, len(boards))
+		}
+		selected[i] = true
+	}
+	var subset []board
+	for i, b := range boards {
+		if selected[i] {
+			subset = append(subset, b)
+		}
+	}
+	return subset, nil
+}
+
+// sortBoards returns the boards ordered by key: "index" keeps the input
+// order, "score" puts the highest unmarked sum first. Ties always fall back
+// to the input order so output is reproducible.
+func sortBoards(boards []board, key string) ([]board, error) {
+	sorted := cloneBoards(boards)
+	var less func(a, b board) bool
+	switch key {
+	case "index":
+		less = func(a, b board) bool { return a.index < b.index }
+	case "score":
+		less = func(a, b board) bool {
+			if sa, sb := calcBoardScore(a), calcBoardScore(b); sa != sb {
+				return sa > sb
+			}
+			return a.index < b.index
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", key)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
@@ -0,0 +1,981 @@
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sampleInput is the example of the puzzle statement, whose answers are 4512
+// for part 1 and 1924 for part 2
+const sampleInput = `7,4,9,5,11,17,23,2,0,14,21,24,10,16,13,6,15,25,12,22,18,20,8,19,3,26,1
+
+22 13 17 11  0
+ 8  2 23  4 24
+21  9 14 16  7
+ 6 10  3 18  5
+ 1 12 20 15 19
+
+ 3 15  0  2 22
+ 9 18 13 17  5
+19  8  7 25 23
+20 11 10 24  4
+14 21 16 12  6
+
+14 21 17 24  4
+10 16 15  9 19
+18  8 23 26 20
+22 11 13  6  5
+ 2  0 12  3  7
+`
+
+// parseInput parses a text input the way the play command does by default
+func parseInput(t *testing.T, input string) (numbers []int, boards []board) {
+	t.Helper()
+	scanner, dims, err := textInput(strings.NewReader(input), boardSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers, err = parseNumberDraws(scanner, ","); err != nil {
+		t.Fatal(err)
+	}
+	if boards, err = parseNumberBoards(scanner, "", dims); err != nil {
+		t.Fatal(err)
+	}
+	return numbers, boards
+}
+
+// parseBoard parses a single board from whitespace-separated rows
+func parseBoard(t *testing.T, rows string) board {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(rows), "\n")
+	dims := dimensions{len(lines), len(strings.Fields(lines[0]))}
+	boards, err := parseNumberBoards(bufio.NewScanner(strings.NewReader(rows)), "", dims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 1 {
+		t.Fatalf("parsed %d boards, want 1", len(boards))
+	}
+	return boards[0]
+}
+
+// mark returns a copy of the board with the numbers marked
+func mark(b board, numbers ...int) board {
+	boards := []board{b.clone()}
+	for _, number := range numbers {
+		boards = markDrawnNumber(boards, number)
+	}
+	return boards[0]
+}
+
+// writeFile writes content to a file named name in a temporary directory and
+// returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runMain runs the command line tool with args and returns what it printed
+// on stdout and stderr, and its exit status. Settings that commands leave in
+// package variables are restored afterwards.
+func runMain(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outFile.Close()
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer errFile.Close()
+
+	savedOut, savedErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	defer func() { os.Stdout, os.Stderr = savedOut, savedErr }()
+	defer saveGlobals()()
+	code = Main(args)
+
+	out, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.ReadFile(errFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), string(errOut), code
+}
+
+// saveGlobals returns a function restoring the logger and the settings that
+// parsing the arguments of a command leaves in package variables
+func saveGlobals() (restore func()) {
+	savedLogger := slog.Default()
+	savedWin, savedMultiplier, savedTiebreak := winOptions, multiplierMode, lastTiebreak
+	return func() {
+		slog.SetDefault(savedLogger)
+		winOptions, multiplierMode, lastTiebreak = savedWin, savedMultiplier, savedTiebreak
+		scoreMarked, deterministic, profilePhase = false, false, "all"
+		collectTimings, traceDraws, drawDelays = false, false, nil
+	}
+}
+
+// playLines returns the stdout lines of a successful run
+func playLines(t *testing.T, args ...string) []string {
+	t.Helper()
+	stdout, stderr, code := runMain(t, args...)
+	if code != 0 {
+		t.Fatalf("aoc4 %s: exit status %d, stderr:\n%s", strings.Join(args, " "), code, stderr)
+	}
+	return strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+}
+
+// equalLines fails the test unless got and want hold the same lines
+func equalLines(t *testing.T, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("got lines\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// captureLog collects what is logged at level or above while fn runs
+func captureLog(level slog.Level, fn func()) string {
+	var buf bytes.Buffer
+	saved := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level})))
+	defer slog.SetDefault(saved)
+	fn()
+	return buf.String()
+}
+
+func TestSampleAnswers(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	part1 := playBingoBestChoice(cloneBoards(boards), numbers)
+	part2 := playBingoWorstChoice(cloneBoards(boards), numbers)
+	if part1.Score != 4512 || part1.Board.index != 2 || part1.Sum != 188 {
+		t.Errorf("part 1 = board %d, sum %d, score %d, want board 2, 188, 4512", part1.Board.index, part1.Sum, part1.Score)
+	}
+	if part2.Score != 1924 || part2.Board.index != 1 || part2.Sum != 148 {
+		t.Errorf("part 2 = board %d, sum %d, score %d, want board 1, 148, 1924", part2.Board.index, part2.Sum, part2.Score)
+	}
+
+	// part 1 marks the boards it is given, so part 2 needs its own copy
+	played := cloneBoards(boards)
+	playBingoBestChoice(played, numbers)
+	if played[2].markedCount() == 0 || boards[2].markedCount() != 0 {
+		t.Errorf("part 1 marked %d cells of its boards and %d of the parsed ones", played[2].markedCount(), boards[2].markedCount())
+	}
+
+	equalLines(t, playLines(t, writeFile(t, "input", sampleInput)), []string{"part1 result: 4512", "part2 result: 1924"})
+}
+
+func TestParseNumberBoardsDelimiters(t *testing.T) {
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	tests := []struct {
+		name, delim, input string
+	}{
+		{"spaces", "", "1 2 3\n4 5 6\n7 8 9\n"},
+		{"mixed tabs and spaces", "", "  1\t2   3\n4\t\t5 6\n\t7 8\t 9\t\n"},
+		{"tabs", "\t", "1\t2\t3\n4\t5\t6\n7\t8\t9\n"},
+		{"semicolons", ";", "1;2;3\n 4 ; 5 ;6\n7;8; 9\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(tt.input))
+			boards, err := parseNumberBoards(scanner, tt.delim, dimensions{3, 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(boards) != 1 {
+				t.Fatalf("parsed %d boards, want 1", len(boards))
+			}
+			for y, row := range want {
+				if !slices.Equal(boards[0].numbers[y], row) {
+					t.Errorf("row %d = %v, want %v", y, boards[0].numbers[y], row)
+				}
+			}
+		})
+	}
+}
+
+func TestParseNumberBoardsDelimiterMismatch(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("1 2 3\n4 5 6\n7 8 9\n"))
+	if _, err := parseNumberBoards(scanner, ";", dimensions{3, 3}); err == nil {
+		t.Error("space-separated rows parsed with -delim ;")
+	}
+}
+
+func TestBoardAt(t *testing.T) {
+	b := parseBoard(t, "22 13 17\n 8  2 23\n21  9 14\n")
+	b = markDrawnNumber([]board{b}, 22)[0]
+	b = markDrawnNumber([]board{b}, 9)[0]
+	tests := []struct {
+		row, col, value int
+		marked          bool
+	}{
+		{0, 0, 22, true},
+		{0, 1, 13, false},
+		{1, 2, 23, false},
+		{2, 1, 9, true},
+	}
+	for _, tt := range tests {
+		value, marked := b.At(tt.row, tt.col)
+		if value != tt.value || marked != tt.marked {
+			t.Errorf("At(%d, %d) = %d, %t, want %d, %t", tt.row, tt.col, value, marked, tt.value, tt.marked)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	result := playBingoBestChoice(cloneBoards(boards), numbers)
+	if result.Sum != 188 || result.Multiplier != 24 || result.Sum*result.Multiplier != result.Score {
+		t.Errorf("sum %d * multiplier %d != score %d", result.Sum, result.Multiplier, result.Score)
+	}
+	want := "score = sum(188) * lastNumber(24) = 4512, row sums: 0 60 72 41 15, column sums: 50 24 40 35 39"
+	if got := result.explain(); got != want {
+		t.Errorf("explain() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatTranspose(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n40 5 6\n")
+	b = markDrawnNumber([]board{b}, 5)[0]
+	tests := []struct {
+		name string
+		opts renderOptions
+		want string
+	}{
+		{"row-major", renderOptions{}, "  1,  2,  3\n 40,[5],  6\n"},
+		{"column-major", renderOptions{transpose: true}, "  1, 40\n  2,[5]\n  3,  6\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.format(tt.opts); got != tt.want {
+				t.Errorf("format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreWith(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n")
+	b = markDrawnNumber([]board{b}, 5)[0]
+	b = markDrawnNumber([]board{b}, 9)[0]
+	tests := []struct {
+		name    string
+		weights weightMatrix
+		want    int
+	}{
+		{"unweighted", nil, 31},
+		{"uniform", weightMatrix{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}, 31},
+		{"center and corners", weightMatrix{{2, 1, 2}, {1, 5, 1}, {2, 1, 2}}, 2*1 + 2 + 2*3 + 4 + 6 + 2*7 + 8},
+		{"zero weights", weightMatrix{{0, 0, 0}, {0, 1, 0}, {0, 0, 1}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreWith(b, tt.weights); got != tt.want {
+				t.Errorf("scoreWith() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if got := calcBoardScore(b); got != 31 {
+		t.Errorf("calcBoardScore() = %d, want 31", got)
+	}
+}
+
+func TestSelectBoards(t *testing.T) {
+	var boards []board
+	for i := 0; i < 5; i++ {
+		b := parseBoard(t, fmt.Sprintf("%d %d\n%d %d\n", 4*i, 4*i+1, 4*i+2, 4*i+3))
+		b.index = i
+		boards = append(boards, b)
+	}
+	selected, err := selectBoards(boards, []int{3, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indices []int
+	for _, b := range selected {
+		indices = append(indices, b.index)
+	}
+	if want := []int{1, 3}; !slices.Equal(indices, want) {
+		t.Errorf("selected boards %v, want %v", indices, want)
+	}
+	// board 0 would win first on its own, board 3 wins before board 1
+	result := playBingoBestChoice(selected, []int{0, 1, 12, 13, 4, 5})
+	if !result.Won || result.Board.index != 3 || result.Draw != 3 {
+		t.Errorf("part 1 winner: board %d on draw %d, want board 3 on draw 3", result.Board.index, result.Draw)
+	}
+	if _, err := selectBoards(boards, []int{1, 5}); err == nil {
+		t.Error("selected out of range index 5")
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-only", "0,1", input), []string{"part1 result: 2192", "part2 result: 1924"})
+	if _, stderr, code := runMain(t, "-only", "3", input); code != 1 || !strings.Contains(stderr, "out of range") {
+		t.Errorf("-only 3: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+// grid returns rows x cols consecutive numbers from first, one row per line
+func grid(rows, cols, first int) string {
+	var sb strings.Builder
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			fmt.Fprintf(&sb, "%3d", first+y*cols+x)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestDimensionsHeader(t *testing.T) {
+	input := "dim 6x6\n1,2,3,4,5,6\n\n" + grid(6, 6, 1) + "\n" + grid(6, 6, 37)
+	numbers, boards := parseInput(t, input)
+	if len(numbers) != 6 || len(boards) != 2 {
+		t.Fatalf("parsed %d draws and %d boards, want 6 and 2", len(numbers), len(boards))
+	}
+	for _, b := range boards {
+		if len(b.numbers) != 6 || len(b.numbers[0]) != 6 {
+			t.Errorf("board %d is %dx%d, want 6x6", b.index, len(b.numbers), len(b.numbers[0]))
+		}
+	}
+	// the first row of the first board wins, with 7..36 unmarked
+	if result := playBingoBestChoice(boards, numbers); result.Score != 645*6 {
+		t.Errorf("part 1 score = %d, want %d", result.Score, 645*6)
+	}
+
+	// the header also wins over -size
+	file := writeFile(t, "input", input)
+	equalLines(t, playLines(t, "-size", "3", file)[:1], []string{"part1 result: 3870"})
+
+	mismatched := writeFile(t, "mismatched", "dim 6x6\n1,2,3\n\n"+grid(5, 5, 1))
+	if _, _, code := runMain(t, "validate", mismatched); code == 0 {
+		t.Error("5x5 board accepted under a dim 6x6 header")
+	}
+}
+
+func TestForEachBoard(t *testing.T) {
+	_, boards := parseInput(t, sampleInput)
+	var seen []int
+	err := ForEachBoard(boards, func(i int, b board) error {
+		if b.index != i {
+			t.Errorf("callback %d got board %d", i, b.index)
+		}
+		seen = append(seen, i)
+		return nil
+	})
+	if err != nil || !slices.Equal(seen, []int{0, 1, 2}) {
+		t.Errorf("ForEachBoard() = %v after boards %v, want nil after [0 1 2]", err, seen)
+	}
+
+	stop := errors.New("stop")
+	seen = nil
+	err = ForEachBoard(boards, func(i int, b board) error {
+		seen = append(seen, i)
+		if i == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || !slices.Equal(seen, []int{0, 1}) {
+		t.Errorf("ForEachBoard() = %v after boards %v, want stop after [0 1]", err, seen)
+	}
+}
+
+func TestMarkedCells(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	result := playBingoBestChoice(cloneBoards(boards), numbers)
+	// board 2 after the first 12 draws, with its top row complete
+	want := [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 3}, {2, 2}, {3, 1}, {3, 4}, {4, 0}, {4, 1}, {4, 4}}
+	if !slices.Equal(result.MarkedCells, want) {
+		t.Errorf("MarkedCells = %v, want %v", result.MarkedCells, want)
+	}
+	drawn := numbers[:result.Draw+1]
+	for _, cell := range result.MarkedCells {
+		if val := boards[2].numbers[cell[0]][cell[1]]; !slices.Contains(drawn, val) {
+			t.Errorf("cell %v holds %d, which was not drawn", cell, val)
+		}
+	}
+
+	var lines []string
+	for _, cell := range want {
+		lines = append(lines, fmt.Sprintf("%d,%d", cell[0], cell[1]))
+	}
+	equalLines(t, playLines(t, "-marked-coords", writeFile(t, "input", sampleInput)), lines)
+}
+
+func TestNegativeOneIsANumber(t *testing.T) {
+	b := parseBoard(t, "-1 2\n3 4\n")
+	if _, marked := b.At(0, 0); marked {
+		t.Fatal("-1 cell parsed as marked")
+	}
+	// 2 and 3 complete no line unless -1 counted as marked
+	if result := playBingoBestChoice([]board{b.clone()}, []int{2, 3}); result.Won {
+		t.Errorf("won on %v without drawing -1", result.MarkedCells)
+	}
+	// the undrawn -1 still counts towards the score
+	if result := playBingoBestChoice([]board{b.clone()}, []int{2, 4}); result.Score != (-1+3)*4 {
+		t.Errorf("score = %d, want %d", result.Score, (-1+3)*4)
+	}
+	if result := playBingoBestChoice([]board{b.clone()}, []int{-1, 2}); !result.Won || result.Score != 7*2 {
+		t.Errorf("drawing -1: %v, want 14", result)
+	}
+}
+
+func TestFormatBox(t *testing.T) {
+	b := mark(parseBoard(t, "1 2\n30 4\n"), 30)
+	tests := []struct {
+		name string
+		opts renderOptions
+		want string
+	}{
+		{"unicode", renderOptions{box: true}, `┌──────┬──────┐
+│    1 │    2 │
+├──────┼──────┤
+│ [30] │    4 │
+└──────┴──────┘
+`},
+		{"ascii", renderOptions{box: true, ascii: true}, `+------+------+
+|    1 |    2 |
++------+------+
+| [30] |    4 |
++------+------+
+`},
+		{"color", renderOptions{box: true, color: true}, "┌────┬────┐\n│  1 │  2 │\n├────┼────┤\n│ " +
+			ansiMarked + "30" + ansiReset + " │  4 │\n└────┴────┘\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.format(tt.opts); got != tt.want {
+				t.Errorf("format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMarked(t *testing.T) {
+	b := mark(parseBoard(t, "1 2\n30 4\n"), 30, 4)
+	tests := []struct {
+		name string
+		opts renderOptions
+		want string
+	}{
+		{"plain", renderOptions{}, "   1,   2\n[30], [4]\n"},
+		{"color", renderOptions{color: true}, "  1,  2\n " + ansiMarked + "30" + ansiReset + ",  " +
+			ansiMarked + "4" + ansiReset + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.format(tt.opts)
+			if got != tt.want {
+				t.Errorf("format() = %q, want %q", got, tt.want)
+			}
+			if tt.opts.color && strings.Contains(got, "[30]") {
+				t.Errorf("format() = %q uses brackets alongside colors", got)
+			}
+		})
+	}
+}
+
+func TestIsValidInput(t *testing.T) {
+	draws, _, _ := strings.Cut(sampleInput, "\n")
+	tests := []struct {
+		name, input string
+		valid       bool
+		err         string
+	}{
+		{"sample", sampleInput, true, ""},
+		{"dimensions header", "dim 2x2\n1,2\n\n1 2\n3 4\n", true, ""},
+		{"missing draws", sampleBoards(), false, "no number draws found"},
+		{"empty", "", false, "no number draws found"},
+		{"no boards", draws + "\n", false, "no complete boards found"},
+		{"incomplete board", draws + "\n\n22 13 17 11  0\n 8  2 23  4 24\n", false, "2 of 5 rows"},
+		{"short row", draws + "\n\n22 13 17 11\n", false, "expected 5 numbers, got 4"},
+		{"bad header", "dim 2y2\n1,2\n", false, "dim"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := IsValidInput(strings.NewReader(tt.input))
+			if valid != tt.valid || (err == nil) != tt.valid {
+				t.Fatalf("IsValidInput() = %t, %v, want %t", valid, err, tt.valid)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %q does not mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFormatNumbered(t *testing.T) {
+	b := mark(parseBoard(t, "1 2 3\n40 5 6\n"), 5)
+	tests := []struct {
+		name string
+		opts renderOptions
+		want string
+	}{
+		{"rows", renderOptions{numbered: true}, "     0   1   2\n0:   1,  2,  3\n1:  40,[5],  6\n"},
+		{"transposed", renderOptions{numbered: true, transpose: true}, "     0   1\n0:   1, 40\n1:   2,[5]\n2:   3,  6\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.format(tt.opts); got != tt.want {
+				t.Errorf("format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowScores(t *testing.T) {
+	b := mark(parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n"), 2, 4, 5, 6, 9)
+	if got, want := rowScores(b), []int{4, 0, 15}; !slices.Equal(got, want) {
+		t.Errorf("rowScores() = %v, want %v", got, want)
+	}
+	if got, want := colScores(b), []int{8, 8, 3}; !slices.Equal(got, want) {
+		t.Errorf("colScores() = %v, want %v", got, want)
+	}
+	t.Cleanup(func() { scoreMarked = false })
+	scoreMarked = true
+	if got, want := rowScores(b), []int{2, 15, 9}; !slices.Equal(got, want) {
+		t.Errorf("rowScores() with scoreMarked = %v, want %v", got, want)
+	}
+}
+
+func TestColScores(t *testing.T) {
+	b := mark(parseBoard(t, "1 2 3 4\n5 6 7 8\n"), 1, 6, 7, 4)
+	if got, want := colScores(b), []int{5, 2, 3, 8}; !slices.Equal(got, want) {
+		t.Errorf("colScores() = %v, want %v", got, want)
+	}
+	if got := colScores(mark(b, 2, 3, 5, 8)); !slices.Equal(got, []int{0, 0, 0, 0}) {
+		t.Errorf("colScores() of a fully marked board = %v", got)
+	}
+	t.Cleanup(func() { scoreMarked = false })
+	scoreMarked = true
+	if got, want := colScores(b), []int{1, 6, 7, 4}; !slices.Equal(got, want) {
+		t.Errorf("colScores() with scoreMarked = %v, want %v", got, want)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	stdout, _, code := runMain(t, "-format", "tsv", "-verbose", input)
+	if code != 0 {
+		t.Fatalf("exit status %d", code)
+	}
+	want := "part\tscore\tnumber\tdraw\n1\t4512\t24\t11\n2\t1924\t13\t14\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if got := (GameResult{}).tsv(1); got != "1\t\t\t" {
+		t.Errorf("no winner: tsv() = %q, want 4 fields", got)
+	}
+
+	b := mark(parseBoard(t, "1 2 3\n40 5 6\n"), 5, 40)
+	got := b.format(renderOptions{tsv: true})
+	for _, row := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		for _, cell := range strings.Split(row, "\t") {
+			if cell == "" || strings.TrimSpace(cell) != cell {
+				t.Errorf("cell %q of row %q is empty or padded", cell, row)
+			}
+		}
+	}
+	if want := "1\t2\t3\n40*\t5*\t6\n"; got != want {
+		t.Errorf("format() = %q, want %q", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	b := mark(parseBoard(t, "22 13 17\n 8  2 23\n21  9 14\n"), 9)
+	tests := []struct {
+		value, row, col int
+		ok              bool
+	}{
+		{22, 0, 0, true},
+		{23, 1, 2, true},
+		{9, 2, 1, true}, // marked cells keep their value
+		{99, 0, 0, false},
+		{-1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		row, col, ok := b.find(tt.value)
+		if ok != tt.ok || (ok && (row != tt.row || col != tt.col)) {
+			t.Errorf("find(%d) = %d, %d, %t, want %d, %d, %t", tt.value, row, col, ok, tt.row, tt.col, tt.ok)
+		}
+	}
+}
+
+func TestJSONInput(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	in := jsonInput{Draws: numbers}
+	for _, b := range boards {
+		in.Boards = append(in.Boards, b.numbers)
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonNumbers, jsonBoards, err := parseJSONInput(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(jsonNumbers, numbers) || len(jsonBoards) != len(boards) {
+		t.Fatalf("parsed %d draws and %d boards, want %d and %d", len(jsonNumbers), len(jsonBoards), len(numbers), len(boards))
+	}
+	for i := range boards {
+		if !sameNumbers(jsonBoards[i], boards[i]) || jsonBoards[i].index != i {
+			t.Errorf("board %d =\n%swant\n%s", i, jsonBoards[i], boards[i])
+		}
+	}
+
+	text := playLines(t, writeFile(t, "input", sampleInput))
+	equalLines(t, playLines(t, "-input-format", "json", writeFile(t, "input.json", string(data))), text)
+
+	for _, bad := range []string{`{"draws": [1], "boards": [[[1, 2], [3]]]}`, `{"draws": [1], "boards": [[[1]], [[1, 2]]]}`, `not json`} {
+		if _, _, err := parseJSONInput(strings.NewReader(bad)); err == nil {
+			t.Errorf("parsed %s", bad)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	_, named := parseInput(t, namedSampleInput())
+	boards := append(named, mark(parseBoard(t, "1 *  3\n4 5 -6\n100 8 9\n"), 1, 5))
+	for _, b := range boards {
+		again, err := parseNumberBoards(bufio.NewScanner(strings.NewReader(b.Marshal())), "", b.dimensions())
+		if err != nil {
+			t.Fatalf("board %d: %v\n%s", b.index, err, b.Marshal())
+		}
+		if len(again) != 1 {
+			t.Fatalf("board %d: parsed %d boards from\n%s", b.index, len(again), b.Marshal())
+		}
+		got := again[0]
+		if !sameNumbers(got, b) || got.name != b.name {
+			t.Errorf("board %d: round trip gave\n%swant\n%s", b.index, got.Marshal(), b.Marshal())
+		}
+		for y := range b.numbers {
+			for x := range b.numbers[y] {
+				if got.isFree(y, x) != b.isFree(y, x) {
+					t.Errorf("board %d: cell %d,%d free = %t, want %t", b.index, y, x, got.isFree(y, x), b.isFree(y, x))
+				}
+				if _, marked := got.At(y, x); marked != b.isFree(y, x) {
+					t.Errorf("board %d: cell %d,%d marked after the round trip", b.index, y, x)
+				}
+			}
+		}
+	}
+	if want := "Board: Alice\n22 13 17 11  0\n 8  2 23  4 24\n21  9 14 16  7\n 6 10  3 18  5\n 1 12 20 15 19\n"; named[0].Marshal() != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", named[0].Marshal(), want)
+	}
+}
+
+func TestMarkedCount(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n")
+	tests := []struct {
+		marked []int
+		want   int
+	}{
+		{nil, 0},
+		{[]int{2, 6}, 2},
+		{[]int{2, 6, 99, 2}, 2}, // undrawn and repeated numbers mark nothing new
+		{[]int{1, 2, 3, 4, 5, 6}, 6},
+	}
+	for _, tt := range tests {
+		if got := mark(b, tt.marked...).markedCount(); got != tt.want {
+			t.Errorf("markedCount() after %v = %d, want %d", tt.marked, got, tt.want)
+		}
+	}
+	if free := parseBoard(t, "1 *\n3 4\n"); free.markedCount() != 1 {
+		t.Errorf("free cell: markedCount() = %d, want 1", free.markedCount())
+	}
+}
+
+func TestMarkMask(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n")
+	tests := []struct {
+		marked []int
+		want   uint64
+	}{
+		{nil, 0},
+		{[]int{1}, 0b000001},
+		{[]int{3, 4}, 0b001100}, // bit row*cols+col
+		{[]int{1, 2, 3, 4, 5, 6}, 0b111111},
+	}
+	for _, tt := range tests {
+		if got, err := mark(b, tt.marked...).markMask(); err != nil || got != tt.want {
+			t.Errorf("markMask() after %v = %#b, %v, want %#b", tt.marked, got, err, tt.want)
+		}
+	}
+	if _, err := parseBoard(t, grid(5, 13, 1)).markMask(); err == nil {
+		t.Error("markMask() of a 65-cell board succeeded")
+	}
+}
+
+func TestProfilePhase(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	tests := []struct {
+		phase string
+		want  []string
+	}{
+		{"parse", []string{"parseNumberDraws", "parseNumberBoards"}},
+		{"part1", []string{"playBingoBestChoice"}},
+		{"part2", []string{"playBingoWorstChoice"}},
+		{"all", []string{"parseNumberDraws", "parseNumberBoards", "playBingoBestChoice", "playBingoWorstChoice", "main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			// a single phase prints its timings at the default log level,
+			// all of them are logged at debug level
+			args := []string{"-profile-phase", tt.phase, input}
+			prefix, end := "timing ", ":"
+			if tt.phase == "all" {
+				args = append([]string{"-log-level", "debug"}, args...)
+				prefix, end = "msg=duration func=", " "
+			}
+			_, stderr, code := runMain(t, args...)
+			if code != 0 {
+				t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+			}
+			var timed []string
+			for _, line := range strings.Split(stderr, "\n") {
+				if _, rest, ok := strings.Cut(line, prefix); ok {
+					name, _, _ := strings.Cut(rest, end)
+					timed = append(timed, name)
+				}
+			}
+			if !slices.Equal(timed, tt.want) {
+				t.Errorf("timed %v, want %v", timed, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeCells(t *testing.T) {
+	input := "dim 3x3\n4,6,2,8\n\n1 2 3\n4 5 6\n7 8 9\n"
+	numbers, boards := parseInput(t, input)
+	if result := playBingoBestChoice(cloneBoards(boards), numbers); result.Won {
+		t.Fatalf("won without a free center: %v", result)
+	}
+	// with 5 free, row 1 is complete on the second draw; the free cell never
+	// counts towards the score: (1+2+3+7+8+9) * 6
+	file := writeFile(t, "input", input)
+	equalLines(t, playLines(t, "-free-center", file)[:1], []string{"part1 result: 180"})
+
+	wildcard := parseBoard(t, "1 2 3\n4 * 6\n7 8 9\n")
+	if !wildcard.isFree(1, 1) {
+		t.Fatal("* did not parse as a free cell")
+	}
+	if result := playBingoBestChoice([]board{wildcard}, numbers); result.Draw != 1 || result.Score != 180 {
+		t.Errorf("wildcard board: %v on draw index %d, want 180 on draw index 1", result, result.Draw)
+	}
+
+	if _, stderr, code := runMain(t, "-free-center", writeFile(t, "even", "dim 2x2\n1,2\n\n1 2\n3 4\n")); code == 0 {
+		t.Errorf("-free-center on an even-sized board succeeded, stderr:\n%s", stderr)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	_, boards := parseInput(t, sampleInput)
+	copies := []board{boards[0].clone(), mark(boards[0], 22, 13)}
+	copies[1].index, copies[1].name = 7, "copy"
+	for _, c := range copies {
+		if c.checksum() != boards[0].checksum() {
+			t.Errorf("copy of board 0 has checksum %x, want %x", c.checksum(), boards[0].checksum())
+		}
+	}
+	seen := map[uint64]int{}
+	for _, b := range boards {
+		if other, ok := seen[b.checksum()]; ok {
+			t.Errorf("boards %d and %d share checksum %x", other, b.index, b.checksum())
+		}
+		seen[b.checksum()] = b.index
+	}
+	swapped := parseBoard(t, "2 1\n3 4\n")
+	if swapped.checksum() == parseBoard(t, "1 2\n3 4\n").checksum() {
+		t.Error("swapping two cells kept the checksum")
+	}
+}
+
+func TestAllocReport(t *testing.T) {
+	var sink [][]int
+	report := measureAllocs(func() {
+		for i := 0; i < 100; i++ {
+			sink = append(sink, make([]int, 128))
+		}
+	})
+	if report.objects < 100 || report.bytes < 100*128*8 {
+		t.Errorf("measureAllocs() = %+v, want at least 100 objects of 1KiB", report)
+	}
+
+	stdout, stderr, code := runMain(t, "-alloc-report", writeFile(t, "input", sampleInput))
+	if code != 0 || stdout != "part1 result: 4512\npart2 result: 1924\n" {
+		t.Fatalf("-alloc-report: exit status %d, stdout:\n%s", code, stdout)
+	}
+	if !regexp.MustCompile(`(?m)^allocs: \d+ objects, \d+ bytes$`).MatchString(stderr) {
+		t.Errorf("-alloc-report wrote to stderr:\n%s", stderr)
+	}
+}
+
+func TestScoreMarked(t *testing.T) {
+	t.Cleanup(func() { scoreMarked = false })
+	b := mark(parseBoard(t, "1 * 3\n4 5 6\n"), 1, 3, 5)
+	if got := calcBoardScore(b); got != 10 {
+		t.Errorf("calcBoardScore() = %d, want the unmarked 4+6", got)
+	}
+	scoreMarked = true
+	if got := calcBoardScore(b); got != 9 {
+		t.Errorf("calcBoardScore() with scoreMarked = %d, want the marked 1+3+5 without the free cell", got)
+	}
+	scoreMarked = false
+
+	// the winning boards hold 325 and 324 in all
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-summary", input), []string{"part1=4512(n=24,i=11) part2=1924(n=13,i=14)"})
+	equalLines(t, playLines(t, "-score-marked", "-summary", input), []string{"part1=3288(n=24,i=11) part2=2288(n=13,i=14)"})
+}
+
+func TestWorstChoiceTie(t *testing.T) {
+	// board 0 wins on draw 1, boards 1 and 2 both on the last number 4 with
+	// scores 68 and 56
+	numbers, boards := parseInput(t, "dim 2x2\n1,2,3,4,5\n\n1 2\n5 6\n\n3 9\n4 8\n\n4 3\n7 7\n")
+	result := playBingoWorstChoice(cloneBoards(boards), numbers)
+	if !result.Won || result.Board.index != 1 || result.Draw != 3 || result.Number != 4 || result.Score != 68 {
+		t.Errorf("playBingoWorstChoice() = board %d, draw %d, number %d, score %d, want board 1, 3, 4, 68",
+			result.Board.index, result.Draw, result.Number, result.Score)
+	}
+	if result := PlayLastWin(nil, numbers, Options{}); result.Won {
+		t.Errorf("PlayLastWin() without boards = %v, want no winner", result)
+	}
+}
+
+func TestMarksKeepNumbers(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	result := playBingoBestChoice(cloneBoards(boards), numbers)
+	// the winner's numbers survive marking, only its mask records the draws
+	if !slices.EqualFunc(result.Board.numbers, boards[2].numbers, slices.Equal[[]int]) {
+		t.Errorf("winning board numbers = %v, want %v", result.Board.numbers, boards[2].numbers)
+	}
+	if got := result.Board.markedCount(); got != 12 {
+		t.Errorf("winning board has %d cells marked, want the 12 drawn", got)
+	}
+
+	b := parseBoard(t, "1 2\n3 4\n")
+	marked := markDrawnNumber([]board{b.clone()}, 2)[0]
+	if _, ok := b.At(0, 1); ok {
+		t.Error("marking a clone marked the original board")
+	}
+	if val, ok := marked.At(0, 1); val != 2 || !ok {
+		t.Errorf("marked cell = %d, %t, want 2, true", val, ok)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct{ name, input, err string }{
+		{"draws", "7,x,9\n\n" + grid(5, 5, 1), "draws line 1: invalid number"},
+		{"board number", "dim 2x2\n7,4\n\n1 2\n3 y\n", "board line 3: invalid number"},
+		{"header", "dim 0x5\n7,4\n", "must be at least 1x1"},
+		{"no draws", "\n\n", "no number draws found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseInput(strings.NewReader(tt.input)); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseInput() error = %v, want %q", err, tt.err)
+			}
+			// the command reports the error instead of panicking
+			_, stderr, code := runMain(t, writeFile(t, "input", tt.input))
+			if code != 1 || !strings.Contains(stderr, tt.err) || strings.Contains(stderr, "goroutine") {
+				t.Errorf("exit status %d, stderr:\n%s", code, stderr)
+			}
+		})
+	}
+}
+
+func TestMalformedBoards(t *testing.T) {
+	tests := []struct{ name, boards, err string }{
+		{"short row", "1 2\n3\n", "board line 3: expected 2 numbers, got 1"},
+		{"long row", "1 2\n3 4 5\n", "board line 3: expected 2 numbers, got 3"},
+		{"missing row at the end", "1 2\n", "board line 2: board 0 ends after 1 of 2 rows"},
+		{"missing row before a blank line", "1 2\n\n3 4\n5 6\n", "board line 3: board 0 ends after 1 of 2 rows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseNumberBoards(bufio.NewScanner(strings.NewReader("\n"+tt.boards)), " ", dimensions{2, 2})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseNumberBoards() error = %v, want %q", err, tt.err)
+			}
+			if _, _, err := ParseInput(strings.NewReader("dim 2x2\n1,2\n\n" + tt.boards)); err == nil {
+				t.Error("ParseInput() accepted the board")
+			}
+			_, stderr, code := runMain(t, writeFile(t, "input", "dim 2x2\n1,2\n\n"+tt.boards))
+			if code != 1 || !strings.Contains(stderr, tt.err) {
+				t.Errorf("exit status %d, stderr:\n%s", code, stderr)
+			}
+		})
+	}
+}
+
+func TestRepeatedDraws(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// 7 and 4 are drawn twice, 99 and 1000 are on no board
+	draws := append([]int{7, 7, 4, 99, 4, 1000}, numbers[2:]...)
+	first, last := playBoth(cloneBoards(boards), draws)
+	if first.Score != 4512 || first.Number != 24 || first.Draw != 11+4 {
+		t.Errorf("part 1 = score %d, number %d, draw %d, want 4512, 24, 15", first.Score, first.Number, first.Draw)
+	}
+	if last.Score != 1924 || last.Number != 13 || last.Draw != 14+4 {
+		t.Errorf("part 2 = score %d, number %d, draw %d, want 1924, 13, 18", last.Score, last.Number, last.Draw)
+	}
+
+	b := mark(parseBoard(t, "1 2\n3 4\n"), 2)
+	again := markDrawnNumber([]board{b.clone()}, 2)[0]
+	if !slices.EqualFunc(again.marked, b.marked, slices.Equal[[]bool]) || calcBoardScore(again) != calcBoardScore(b) {
+		t.Errorf("drawing 2 again changed the board: %v", again)
+	}
+}
+
+func TestOptionsIgnoreFlags(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// the exported API plays by its options, whatever the flags last set
+	t.Cleanup(saveGlobals())
+	winOptions = WinOptions{Corners: true, Lines: 1}
+	multiplierMode, scoreMarked, lastTiebreak = "count-drawn", true, "lowest"
+	if got := PlayFirstWin(boards, numbers, Options{}); got.Score != 4512 || got.Multiplier != 24 {
+		t.Errorf("PlayFirstWin() = %v times %d, want 4512 times 24", got, got.Multiplier)
+	}
+	if got := PlayLastWin(boards, numbers, Options{}); got.Score != 1924 {
+		t.Errorf("PlayLastWin() = %v, want 1924", got)
+	}
+	if first, last := PlayBoth(boards, numbers, Options{}); first != 4512 || last != 1924 {
+		t.Errorf("PlayBoth() = %d, %d, want 4512, 1924", first, last)
+	}
+	if got := Score(boards[0], Options{}); got != 300 {
+		t.Errorf("Score() = %d, want 300", got)
+	}
+
+	// and the options select the same rules as the flags
+	opts := Options{Win: WinOptions{Rows: true, Columns: true, Diagonals: true, Lines: 1}, Multiplier: "count-drawn", ScoreMarked: true}
+	winOptions = opts.Win
+	want := playBingoBestChoice(cloneBoards(boards), numbers)
+	if got := PlayFirstWin(boards, numbers, opts); got.Score != want.Score || got.Draw != want.Draw {
+		t.Errorf("PlayFirstWin() = %v on draw %d, want %v on draw %d", got, got.Draw, want, want.Draw)
+	}
+	if got, want := Score(mark(boards[0], 22, 13), opts), 35; got != want {
+		t.Errorf("Score() with ScoreMarked = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package bingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// cacheFile stores results of earlier -cache runs in the working directory
+const cacheFile = ".aoc4cache"
+
+// cacheVersion is part of every cache key. Bump it when a fix changes the
+// results of some input, so entries stored before the fix are missed.
+const cacheVersion = 3
+
+// cachedPart is the part of a GameResult needed to print a cached result
+type cachedPart struct {
+	Won   bool   `json:"won"`
+	Score int    `json:"score"`
+	Name  string `json:"name,omitempty"`
+}
+
+func newCachedPart(r GameResult) cachedPart {
+	return cachedPart{r.Won, r.Score, r.Board.name}
+}
+
+func (p cachedPart) String() string {
+	return GameResult{Won: p.Won, Score: p.Score, Board: board{name: p.Name}}.String()
+}
+
+type cachedResult struct {
+	Part1 cachedPart `json:"part1"`
+	Part2 cachedPart `json:"part2"`
+}
+
+// cacheKey hashes the input file, or the -boards-dir files, and the draws
+// file if any, together with every option that can change the results, so
+// that editing any of these files or playing with different options misses
+// the cache
+func cacheKey(opts inputOptions) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d|", cacheVersion)
+	filenames := []string{opts.filename}
+	if opts.boardsDir != "" {
+		var err error
+		if filenames, err = boardFiles(opts.boardsDir); err != nil {
+			return "", err
+		}
+	}
+	for _, filename := range append(filenames, opts.drawsFile, opts.timedDraws) {
+		if filename == "" {
+			continue
+		}
+		data, err := readInputData(filename)
+		if err != nil {
+			return "", err
+		}
+		// with -boards-dir, renaming a file renames its unlabeled board
+		fmt.Fprintf(h, "%s:%d:", filepath.Base(filename), len(data))
+		h.Write(data)
+	}
+	// options that only affect diagnostics are not part of the key
+	opts.filename, opts.filenames, opts.logLevel = "", nil, ""
+	opts.explainParse, opts.profilePhase = false, ""
+	env, _ := os.LookupEnv(drawsEnv)
+	fmt.Fprintf(h, "%+v|%s|%s", opts, env, lastTiebreak)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCache loads the cache store; a missing file is an empty cache
+func readCache() (map[string]cachedResult, error) {
+	cache := map[string]cachedResult{}
+	data, err := os.ReadFile(cacheFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("%s: %w", cacheFile, err)
+	}
+	return cache, nil
+}
+
+func writeCache(cache map[string]cachedResult) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, append(data, '\n'), 0o644)
+}
+
+// cachedPlay returns both parts' results for the input, from the cache when
+// the input and options are unchanged, and reports whether it was a hit
+func cachedPlay(opts inputOptions) (result cachedResult, hit bool, err error) {
+	key, err := cacheKey(opts)
+	if err != nil {
+		return result, false, err
+	}
+	cache, err := readCache()
+	if err != nil {
+		return result, false, err
+	}
+	if result, ok := cache[key]; ok {
+		return result, true, nil
+	}
+	played := playFile(opts)
+	if played.err != nil {
+		return result, false, played.err
+	}
+	result = cachedResult{
+		Part1: newCachedPart(played.part1),
+		Part2: newCachedPart(played.part2),
+	}
+	cache[key] = result
+	return result, false, writeCache(cache)
+}
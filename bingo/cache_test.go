@@ -0,0 +1,69 @@
+package bingo
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// inTempDir runs the rest of the test in a new temporary directory
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// cacheHit runs a -cache play and reports whether it was served from the
+// cache, failing unless it prints the expected results
+func cacheHit(t *testing.T, want []string, args ...string) bool {
+	t.Helper()
+	stdout, stderr, code := runMain(t, append([]string{"-cache", "-log-level", "debug"}, args...)...)
+	if code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+	}
+	equalLines(t, strings.Split(strings.TrimSuffix(stdout, "\n"), "\n"), want)
+	return strings.Contains(stderr, "msg=cache") && strings.Contains(stderr, "hit=true")
+}
+
+func TestCache(t *testing.T) {
+	inTempDir(t)
+	if err := os.WriteFile("input", []byte(namedSampleInput()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"part1 result: 4512 (Carol)", "part2 result: 1924 (Bob)"}
+	if cacheHit(t, want, "input") {
+		t.Error("first run hit the cache")
+	}
+	if !cacheHit(t, want, "input") {
+		t.Error("second run missed the cache")
+	}
+	// other options are cached separately
+	if cacheHit(t, want, "-draw-count", "20", "input") {
+		t.Error("-draw-count 20 hit the cache of a full game")
+	}
+
+	// without its winner, board 0 wins part 1
+	blocks := strings.Split(namedSampleInput(), "\n\n")
+	if err := os.WriteFile("input", []byte(strings.Join(blocks[:3], "\n\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed := []string{"part1 result: 2192 (Alice)", "part2 result: 1924 (Bob)"}
+	if cacheHit(t, changed, "input") {
+		t.Error("modified input hit the cache")
+	}
+	if !cacheHit(t, changed, "input") {
+		t.Error("second run on the modified input missed the cache")
+	}
+
+	for _, args := range [][]string{{"-json"}, {"-format", "tsv"}, {"-summary"}, {"-quiet"}} {
+		if _, _, code := runMain(t, append(append([]string{"-cache"}, args...), "input")...); code != 2 {
+			t.Errorf("-cache %s: exit status %d, want 2", strings.Join(args, " "), code)
+		}
+	}
+}
@@ -0,0 +1,1265 @@
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commands maps subcommand names to their entry points. Running without a
+// subcommand is the same as running "play".
+var commands = map[string]func(args []string) error{
+	"play":     runPlay,
+	"validate": runValidate,
+	"generate": runGenerate,
+	"analyze":  runAnalyze,
+}
+
+// inputOptions are the flags shared by every subcommand reading an input file
+type inputOptions struct {
+	filename     string
+	filenames    []string // every positional argument, filename is the first
+	logLevel     string
+	delim        string
+	drawsDelim   string
+	drawsFile    string
+	drawsArg     string
+	drawsList    []int // drawsArg parsed
+	boardsDir    string
+	timedDraws   string
+	realtime     bool
+	noDrawsLine  bool
+	drawsLine    int
+	drawCount    int
+	maxBoards    int
+	dedupe       dedupeMode
+	explainParse bool
+	only         string
+	filter       string
+	size         int
+	inputFormat  string
+	multiplier   string
+	scoreMarked  bool
+	profilePhase string
+	freeCenter   bool
+	zeroIsFree   bool
+	strict       bool
+	determinism  bool
+	win          WinOptions
+}
+
+func (o *inputOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.logLevel, "log-level", "warn", "log level: debug, info, warn or error")
+	fs.StringVar(&o.delim, "delim", "", "single-character board cell delimiter (default: whitespace)")
+	fs.StringVar(&o.drawsDelim, "delim-draws", ",", "single-character number draws delimiter")
+	fs.StringVar(&o.drawsFile, "draws", "", "read number draws from `FILE` instead of the input file")
+	fs.StringVar(&o.drawsArg, "draws-from-args", "", "play the -delim-draws separated number draws in `LIST` instead of the input file's")
+	fs.StringVar(&o.boardsDir, "boards-dir", "", "read one board from every file in `DIR`, in name order, instead of the input file; draws come from -draws")
+	fs.StringVar(&o.timedDraws, "timed-draws", "", "read \"timestamp,number\" draw lines from `FILE` instead of the input file")
+	fs.BoolVar(&o.realtime, "realtime", false, "pause between -timed-draws draws for their timestamp deltas")
+	fs.BoolVar(&o.noDrawsLine, "no-draws-line", false, "the input file has no number draws line (implied by -draws, -timed-draws and -draws-from-args)")
+	fs.IntVar(&o.drawsLine, "draws-line", 0, "read number draws from line `N` of the input, counting from 1, instead of the first line containing -delim-draws")
+	fs.Var(&o.dedupe, "dedupe-draws", "drop consecutive duplicate draws, or every repeated draw with -dedupe-draws=all")
+	fs.IntVar(&o.drawCount, "draw-count", 0, "only consider the first N drawn numbers (0 = all)")
+	fs.IntVar(&o.maxBoards, "max-boards", 0, "fail if the input holds more than `N` boards (0 = unlimited)")
+	fs.StringVar(&o.inputFormat, "input-format", "text", "input file format: text or json")
+	fs.IntVar(&o.size, "size", boardSize, "rows and columns of each board, unless the input has a \"dim RxC\" header")
+	fs.BoolVar(&o.win.Rows, "rows", true, "a fully marked row completes a line")
+	fs.BoolVar(&o.win.Columns, "columns", true, "a fully marked column completes a line")
+	fs.BoolVar(&o.win.Diagonals, "diagonals", false, "a fully marked diagonal completes a line (square boards only)")
+	fs.BoolVar(&o.win.Corners, "corners", false, "four marked corners complete a line")
+	fs.IntVar(&o.win.Lines, "lines", 1, "completed lines needed to win")
+	fs.StringVar(&o.profilePhase, "profile-phase", "all", "only time the parse, part1 or part2 phase and print its timings to stderr, or all (timings are logged at debug level)")
+	fs.StringVar(&o.multiplier, "multiplier", "last", "score factor: last number, sum-drawn or count-drawn")
+	fs.BoolVar(&o.scoreMarked, "score-marked", false, "score the sum of a board's marked numbers instead of its unmarked ones")
+	fs.BoolVar(&o.zeroIsFree, "zero-is-free", false, "make every cell holding 0 a free wildcard, for cards marking the free space with 0")
+	fs.BoolVar(&o.freeCenter, "free-center", false, "make the center cell of every board a free wildcard (odd sizes only)")
+	fs.BoolVar(&o.determinism, "deterministic", false, "make stdout reproducible byte for byte: play inputs serially and default -seed to a fixed value")
+	fs.BoolVar(&o.strict, "strict", false, "warn about suspicious boards, such as a line complete before any draw or numbers never drawn")
+	fs.StringVar(&o.only, "only", "", "play only the boards at these comma-separated `INDICES`")
+	fs.StringVar(&o.filter, "filter", "", "play only the boards matching `EXPR`: contains:N, sum-gt:N or has-line-drawn, joined by commas")
+	fs.BoolVar(&o.explainParse, "explain-parse", false, "print the parsed number draws and boards before continuing")
+}
+
+// parse parses args into the flag set and fills in the input filename from
+// the first positional argument
+func (o *inputOptions) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(o.delim) > 1 {
+		return fmt.Errorf("invalid -delim %q: must be a single character", o.delim)
+	}
+	if o.win.Lines < 1 {
+		return fmt.Errorf("invalid -lines %d: must be at least 1", o.win.Lines)
+	}
+	if !o.win.Rows && !o.win.Columns && !o.win.Diagonals && !o.win.Corners {
+		return errors.New("no win patterns enabled")
+	}
+	winOptions = o.win
+	if _, ok := scoreMultipliers[o.multiplier]; !ok {
+		return fmt.Errorf("invalid -multiplier %q: must be last, sum-drawn or count-drawn", o.multiplier)
+	}
+	multiplierMode = o.multiplier
+	scoreMarked = o.scoreMarked
+	switch o.profilePhase {
+	case "all", "parse", "part1", "part2":
+		profilePhase = o.profilePhase
+	default:
+		return fmt.Errorf("invalid -profile-phase %q: must be parse, part1, part2 or all", o.profilePhase)
+	}
+	deterministic = o.determinism
+	if o.size < 1 {
+		return fmt.Errorf("invalid -size %d: must be at least 1", o.size)
+	}
+	if len(o.drawsDelim) > 1 {
+		return fmt.Errorf("invalid -delim-draws %q: must be a single character", o.drawsDelim)
+	}
+	if o.maxBoards < 0 {
+		return fmt.Errorf("invalid -max-boards %d: must not be negative", o.maxBoards)
+	}
+	if o.drawsLine < 0 {
+		return fmt.Errorf("invalid -draws-line %d: must be at least 1", o.drawsLine)
+	}
+	if o.drawsFile != "" && o.timedDraws != "" {
+		return errors.New("-draws and -timed-draws are mutually exclusive")
+	}
+	if o.drawsArg != "" {
+		if o.drawsFile != "" || o.timedDraws != "" {
+			return errors.New("-draws-from-args, -draws and -timed-draws are mutually exclusive")
+		}
+		for _, field := range splitFields(o.drawsArg, o.drawsDelim) {
+			number, err := strconv.Atoi(field)
+			if err != nil {
+				return fmt.Errorf("invalid -draws-from-args: invalid number %q in %q", field, o.drawsArg)
+			}
+			o.drawsList = append(o.drawsList, number)
+		}
+	}
+	if o.realtime && o.timedDraws == "" {
+		return errors.New("-realtime requires -timed-draws")
+	}
+	if o.boardsDir != "" && o.drawsFile == "" && o.timedDraws == "" && o.drawsArg == "" && os.Getenv(drawsEnv) == "" {
+		return fmt.Errorf("-boards-dir needs draws from -draws, -timed-draws, -draws-from-args or %s", drawsEnv)
+	}
+	if o.inputFormat != "text" && o.inputFormat != "json" {
+		return fmt.Errorf("invalid -input-format %q: must be text or json", o.inputFormat)
+	}
+	o.filenames = fs.Args()
+	if len(o.filenames) == 0 {
+		// read piped input, but never wait on a terminal for it
+		if isTerminal(os.Stdin) {
+			o.filenames = []string{"input"}
+		} else {
+			o.filenames = []string{stdinName}
+		}
+	}
+	o.filename = o.filenames[0]
+	return setupLogging(o.logLevel)
+}
+
+// flagGiven reports whether the named flag was set on the command line
+func flagGiven(fs *flag.FlagSet, name string) (given bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return
+}
+
+// drawsEnv names the environment variable that, when set, overrides the
+// number draws of the input file with a comma-separated list
+const drawsEnv = "AOC4_DRAWS"
+
+// loadInput parses the number draws and boards selected by the options
+func loadInput(opts inputOptions) (numbers []int, boards []board) {
+	if opts.boardsDir != "" {
+		boards = loadBoardsDir(opts, opts.boardsDir)
+	} else {
+		numbers, boards = readInputFile(opts)
+	}
+
+	numbers = overrideDraws(opts, numbers)
+	boards = opts.prepareBoards(boards)
+	if opts.strict {
+		warnSuspicious(boards, numbers)
+	}
+
+	if drawDelays != nil && opts.dedupe != dedupeOff {
+		drawDelays = keptDelays(drawDelays, keptDraws(numbers, opts.dedupe))
+	}
+	numbers = dedupeDraws(numbers, opts.dedupe)
+	if opts.drawCount > 0 && opts.drawCount < len(numbers) {
+		numbers = numbers[:opts.drawCount]
+	}
+	if keep := opts.boardFilter(); keep != nil {
+		boards = filterBoards(boards, numbers, keep)
+	}
+	if opts.explainParse {
+		explainParse(os.Stdout, numbers, boards)
+	}
+	return
+}
+
+// warnSuspicious logs the -strict warnings about the boards: lines complete
+// before any draw and numbers never drawn
+func warnSuspicious(boards []board, numbers []int) {
+	for _, b := range boards {
+		if ok, name := preCompletedLine(b); ok {
+			slog.Warn("board has a line complete before any draw", "board", b.index, "line", name)
+		}
+	}
+	if err := validateUniverse(boards, numbers); err != nil {
+		slog.Warn("boards use numbers outside the draws", "err", err)
+	}
+}
+
+// readInputFile parses the input file in the selected format
+func readInputFile(opts inputOptions) (numbers []int, boards []board) {
+	fd, err := openInput(opts.filename)
+	check(err)
+	defer fd.Close()
+
+	if opts.inputFormat == "json" {
+		numbers, boards, err = parseJSONInput(fd)
+		check(err)
+		check(opts.checkMaxBoards(opts.filename, len(boards)))
+		return
+	}
+	scanner, dims, numbers := textDrawsInput(fd, opts)
+	if opts.maxBoards == 0 {
+		boards, err = parseNumberBoards(scanner, opts.delim, dims)
+		check(opts.inputError(err))
+		return numbers, boards
+	}
+	// stop reading as soon as the limit is exceeded rather than after
+	// parsing the whole input
+	bs := newBoardScanner(scanner, opts.delim, dims)
+	boards = []board{}
+	for {
+		b, ok := bs.next()
+		if !ok {
+			check(opts.inputError(bs.Err()))
+			return
+		}
+		boards = append(boards, b)
+		check(opts.checkMaxBoards(opts.filename, len(boards)))
+	}
+}
+
+// inputError prefixes a parse error of the input file with its name
+func (o inputOptions) inputError(err error) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", o.filename, err)
+	}
+	return nil
+}
+
+// checkMaxBoards fails once more than -max-boards boards have been read
+// from source
+func (o inputOptions) checkMaxBoards(source string, count int) error {
+	if o.maxBoards > 0 && count > o.maxBoards {
+		return fmt.Errorf("%s: more than %d boards (-max-boards)", source, o.maxBoards)
+	}
+	return nil
+}
+
+// prepareBoards applies the wildcard options to freshly parsed boards and
+// keeps only the -only ones
+func (o inputOptions) prepareBoards(boards []board) []board {
+	for i := range boards {
+		check(o.prepareBoard(&boards[i]))
+	}
+	if o.only == "" {
+		return boards
+	}
+	indices, err := parseIntList(o.only, ",")
+	check(err)
+	boards, err = selectBoards(boards, indices)
+	check(err)
+	return boards
+}
+
+// boardFilter returns the -filter predicate, or nil without -filter
+func (o inputOptions) boardFilter() boardPredicate {
+	if o.filter == "" {
+		return nil
+	}
+	keep, err := parseBoardFilter(o.filter)
+	check(err)
+	return keep
+}
+
+// prepareBoard applies the wildcard options to a freshly parsed board
+func (o inputOptions) prepareBoard(b *board) error {
+	if o.zeroIsFree {
+		for y, row := range b.numbers {
+			for x, val := range row {
+				if val == 0 {
+					b.setFree(y, x)
+				}
+			}
+		}
+	}
+	if o.freeCenter {
+		return setFreeCenter(b)
+	}
+	return nil
+}
+
+// setFreeCenter makes the center cell of the board a wildcard. Boards with an
+// even number of rows or columns have no center cell.
+func setFreeCenter(b *board) error {
+	dims := b.dimensions()
+	if dims.rows%2 == 0 || dims.cols%2 == 0 {
+		return fmt.Errorf("board %d is %v and has no center cell", b.index, dims)
+	}
+	b.setFree(dims.rows/2, dims.cols/2)
+	return nil
+}
+
+// textInput reads the optional dimensions header of a text input, defaulting
+// to size x size boards, and returns a scanner over the rest of the input
+func textInput(r io.Reader, size int) (*bufio.Scanner, dimensions, error) {
+	reader := bufio.NewReader(r)
+	dims, ok, err := readDimensionsHeader(reader)
+	if err != nil {
+		return nil, dims, err
+	}
+	if !ok {
+		dims = dimensions{size, size}
+	}
+	return bufio.NewScanner(reader), dims, nil
+}
+
+// textDrawsInput reads the header and number draws line of a text input as
+// selected by the options and returns a scanner positioned over the boards.
+// No draws are read when they come from elsewhere.
+func textDrawsInput(r io.Reader, opts inputOptions) (scanner *bufio.Scanner, dims dimensions, numbers []int) {
+	if opts.drawsLine > 0 {
+		data, err := io.ReadAll(r)
+		check(err)
+		numbers, data, err = cutDrawsLine(data, opts.drawsLine, opts.drawsDelim)
+		check(err)
+		r = bytes.NewReader(data)
+	}
+	scanner, dims, err := textInput(r, opts.size)
+	check(opts.inputError(err))
+	if opts.drawsLine == 0 && opts.drawsFile == "" && opts.timedDraws == "" && opts.drawsArg == "" && !opts.noDrawsLine {
+		numbers, err = parseNumberDraws(scanner, opts.drawsDelim)
+		check(opts.inputError(err))
+	}
+	return
+}
+
+// overrideDraws returns the draws to play given the ones read from the input:
+// -draws, -timed-draws or -draws-from-args override the environment, which
+// overrides the input's draws
+func overrideDraws(opts inputOptions, numbers []int) []int {
+	if opts.timedDraws != "" {
+		var delays []time.Duration
+		numbers, delays = readTimedDraws(opts.timedDraws)
+		if opts.realtime {
+			drawDelays = delays
+		}
+	} else if opts.drawsFile != "" {
+		numbers = readNumberDraws(opts.drawsFile, opts.drawsDelim)
+	} else if opts.drawsArg != "" {
+		numbers = opts.drawsList
+	} else if env, ok := os.LookupEnv(drawsEnv); ok {
+		var err error
+		numbers, err = parseIntList(env, ",")
+		if err != nil {
+			check(fmt.Errorf("%s: %w", drawsEnv, err))
+		}
+	}
+	if len(numbers) == 0 {
+		check(fmt.Errorf("no number draws found in %s, %s or -draws", opts.filename, drawsEnv))
+	}
+	return numbers
+}
+
+// dedupeMode selects which duplicate draws -dedupe-draws drops. As a boolean
+// flag, "-dedupe-draws" alone means dedupeConsecutive.
+type dedupeMode string
+
+const (
+	dedupeOff         dedupeMode = ""
+	dedupeConsecutive dedupeMode = "consecutive"
+	dedupeAll         dedupeMode = "all"
+)
+
+func (m *dedupeMode) String() string { return string(*m) }
+
+func (m *dedupeMode) Set(value string) error {
+	switch value {
+	case "false":
+		*m = dedupeOff
+	case "true", string(dedupeConsecutive):
+		*m = dedupeConsecutive
+	case string(dedupeAll):
+		*m = dedupeAll
+	default:
+		return fmt.Errorf("must be consecutive or all, got %q", value)
+	}
+	return nil
+}
+
+func (m *dedupeMode) IsBoolFlag() bool { return true }
+
+// dedupeDraws drops draws that repeat the previous one, or with dedupeAll any
+// number drawn before. Marking is idempotent, so the winning boards stay the
+// same, though draw indices and the sum-drawn and count-drawn scores shift.
+func dedupeDraws(numbers []int, mode dedupeMode) []int {
+	if mode == dedupeOff {
+		return numbers
+	}
+	kept := keptDraws(numbers, mode)
+	deduped := make([]int, len(kept))
+	for i, draw := range kept {
+		deduped[i] = numbers[draw]
+	}
+	if dropped := len(numbers) - len(deduped); dropped > 0 {
+		slog.Debug("dropped duplicate draws", "mode", mode, "dropped", dropped)
+	}
+	return deduped
+}
+
+// keptDraws returns the indices of the draws that dedupeDraws keeps
+func keptDraws(numbers []int, mode dedupeMode) (kept []int) {
+	seen := map[int]bool{}
+	for i, number := range numbers {
+		switch {
+		case mode == dedupeAll && seen[number]:
+			continue
+		case mode == dedupeConsecutive && i > 0 && numbers[i-1] == number:
+			continue
+		}
+		seen[number] = true
+		kept = append(kept, i)
+	}
+	return
+}
+
+// joinDraws formats the number draws as a comma-separated line
+func joinDraws(numbers []int) string {
+	draws := make([]string, len(numbers))
+	for i, number := range numbers {
+		draws[i] = strconv.Itoa(number)
+	}
+	return strings.Join(draws, ",")
+}
+
+// explainParse dumps the parsed model: the draw list and every board
+func explainParse(w io.Writer, numbers []int, boards []board) {
+	fmt.Fprintf(w, "draws (%d): %s\n", len(numbers), joinDraws(numbers))
+	fmt.Fprintf(w, "boards (%d):\n", len(boards))
+	ForEachBoard(boards, func(_ int, b board) error {
+		if b.name != "" {
+			fmt.Fprintf(w, "board %d (%s):\n", b.index, b.name)
+		} else {
+			fmt.Fprintf(w, "board %d:\n", b.index)
+		}
+		_, err := fmt.Fprint(w, b)
+		return err
+	})
+}
+
+type playOptions struct {
+	inputOptions
+	repeat      int
+	transpose   bool
+	box         bool
+	ascii       bool
+	numbered    bool
+	explain     bool
+	explainWin  bool
+	record      string
+	replay      string
+	concurrency int
+	png         string
+	trace       string
+	winners     bool
+	exhaust     bool
+	sortKey     string
+	firstN      int
+	danger      int
+	feasible    bool
+	compare     string
+	statsJSON   bool
+	ndjson      bool
+	requireWin  bool
+	order       bool
+	pivotal     bool
+	dot         string
+	fingerprint bool
+	marks       bool
+	ties        bool
+	tournament  bool
+	verbose     bool
+	format      string
+	json        bool
+	incremental bool
+	summary     bool
+	target      int
+	without     int
+	nextWinner  bool
+	table       bool
+	syslog      bool
+	quiet       bool
+	perFile     int
+	tiebreak    string
+	stream      bool
+	noColor     bool
+	dumpDraws   bool
+	normalize   bool
+	boardsFile  string
+	animate     bool
+	cache       bool
+	animateAll  bool
+	frameDelay  time.Duration
+	forceColor  bool
+	group       int
+	watch       bool
+	markedOnly  bool
+	allocs      bool
+	watchEvery  time.Duration
+}
+
+func parsePlayArgs(args []string) (opts playOptions, err error) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	opts.register(fs)
+	fs.IntVar(&opts.repeat, "repeat", 1, "play both parts N times and report min/median/max durations")
+	fs.BoolVar(&opts.transpose, "transpose", false, "print boards column-major")
+	fs.BoolVar(&opts.box, "box", false, "print boards in a grid with box-drawing borders")
+	fs.BoolVar(&opts.ascii, "ascii", false, "draw -box borders with ASCII characters")
+	fs.BoolVar(&opts.noColor, "no-color", false, "never highlight marked numbers with ANSI colors (also set by NO_COLOR)")
+	fs.BoolVar(&opts.forceColor, "force-color", false, "highlight marked numbers with ANSI colors even when stdout is not a terminal")
+	fs.BoolVar(&opts.numbered, "numbered", false, "label printed board rows and columns with their indices")
+	fs.BoolVar(&opts.requireWin, "require-winner", false, "exit with an error if either part has no winning board")
+	fs.BoolVar(&opts.explain, "explain", false, "print the factors of each part's score")
+	fs.BoolVar(&opts.explainWin, "explain-win", false, "narrate each part's winning line cell by cell with the draw that marked it")
+	fs.StringVar(&opts.record, "record", "", "write the part 1 game event log as JSON to `FILE`")
+	fs.StringVar(&opts.png, "png", "", "render the part 1 winning board as a PNG image to `FILE`")
+	fs.BoolVar(&opts.winners, "winners", false, "print every board that won on each part's winning draw")
+	fs.StringVar(&opts.sortKey, "sort", "index", "order of multi-board output: index or score")
+	fs.BoolVar(&opts.exhaust, "exhaust", false, "report the draw on which every board has won")
+	fs.IntVar(&opts.concurrency, "concurrency", runtime.NumCPU(), "number of input files played in parallel")
+	fs.StringVar(&opts.trace, "trace", "", "record per-draw durations and write them as JSON to `FILE`")
+	fs.BoolVar(&opts.feasible, "feasible", false, "check without playing that some board can win with the draws and exit")
+	fs.IntVar(&opts.danger, "danger", 0, "rank the undrawn numbers by the boards they would bring to a win after the first `N` draws and exit")
+	fs.IntVar(&opts.firstN, "first-n-draws-stats", 0, "print marking stats after the first `N` draws and exit")
+	fs.StringVar(&opts.compare, "compare", "", "play the input and `FILE2` and report whether their results match")
+	fs.BoolVar(&opts.table, "table", false, "with several inputs, print one aligned row of results per file")
+	fs.BoolVar(&opts.statsJSON, "stats-json", false, "play every input and print aggregate stats as JSON")
+	fs.BoolVar(&opts.ndjson, "ndjson", false, "stream every board win of a full game to stdout as NDJSON and exit")
+	fs.StringVar(&opts.tiebreak, "last-tiebreak", "highest", "part 2 board reported when several win on the final draw: highest, lowest or first-index")
+	fs.BoolVar(&opts.stream, "stream", false, "play part 1 only, reading boards one at a time to bound memory use")
+	fs.BoolVar(&opts.nextWinner, "next-winner", false, "also report the runner-up: the board that would win next after part 1")
+	fs.IntVar(&opts.without, "without", -1, "also report part 1 replayed without the board with input index `N`")
+	fs.IntVar(&opts.target, "target", -1, "also report the draw on which the board with input index `N` first wins")
+	fs.BoolVar(&opts.summary, "summary", false, "print both results on a single compact line instead of the boards")
+	fs.BoolVar(&opts.syslog, "syslog", false, "also send the -summary line to the system logger")
+	fs.BoolVar(&opts.quiet, "quiet", false, "print no results to stdout, e.g. with -syslog or -require-winner")
+	fs.StringVar(&opts.format, "format", "text", "result output format: text, tsv or json")
+	fs.BoolVar(&opts.incremental, "incremental", false, "play part 1 with per-line marked counts instead of rescanning every board after each draw")
+	fs.BoolVar(&opts.json, "json", false, "shorthand for -format json")
+	fs.StringVar(&opts.boardsFile, "boards", "", "play the boards in `FILE` against each line of draws read from stdin")
+	fs.BoolVar(&opts.animate, "animate", false, "redraw every board after each draw on a terminal until the first win")
+	fs.BoolVar(&opts.animateAll, "animate-all", false, "like -animate, but continue until every board has won")
+	fs.DurationVar(&opts.frameDelay, "frame-delay", 300*time.Millisecond, "pause between -animate frames")
+	fs.BoolVar(&opts.cache, "cache", false, "only print the results, reusing them from "+cacheFile+" while the input is unchanged")
+	fs.BoolVar(&opts.normalize, "normalize", false, "print the parsed input in canonical text form and exit")
+	fs.BoolVar(&opts.dumpDraws, "dump-draws", false, "print the parsed number draws as a comma-separated line and exit")
+	fs.BoolVar(&opts.verbose, "verbose", false, "print part 1 progress per draw, with the lines each board can still complete, and each part's winning board")
+	fs.BoolVar(&opts.tournament, "tournament", false, "print a leaderboard of every board ranked by its winning draw")
+	fs.BoolVar(&opts.ties, "simultaneous", false, "print every pair of boards that win on the same draw of a full game")
+	fs.BoolVar(&opts.marks, "marks-per-draw", false, "print how many cells each draw newly marks over all boards")
+	fs.BoolVar(&opts.fingerprint, "fingerprint", false, "print a hash of both parts' outcome, for regression checks, and exit")
+	fs.StringVar(&opts.dot, "dot", "", "write a Graphviz DOT graph of the win order and shared kill shots to `FILE`")
+	fs.BoolVar(&opts.pivotal, "pivotal", false, "print every number that made boards win in a full game, with how many")
+	fs.BoolVar(&opts.order, "order", false, "print every board in the order it won, with its kill shot number")
+	fs.IntVar(&opts.perFile, "boards-per-file", 0, "split the boards into games of `N` boards sharing the draws and print each game's results")
+	fs.IntVar(&opts.group, "group", 0, "print cumulative winner counts after every `K` draws")
+	fs.BoolVar(&opts.watch, "watch-file", false, "re-play the input whenever the file changes")
+	fs.DurationVar(&opts.watchEvery, "watch-interval", 500*time.Millisecond, "how often -watch-file polls the input")
+	fs.BoolVar(&opts.allocs, "alloc-report", false, "print the heap allocations of the run to stderr at the end")
+	fs.BoolVar(&opts.markedOnly, "marked-coords", false, "only print the row,col coordinates marked on the part 1 winning board")
+	fs.StringVar(&opts.replay, "replay", "", "verify and replay a game event log from `FILE` instead of playing")
+	if err = opts.parse(fs, args); err != nil {
+		return
+	}
+	if opts.json {
+		opts.format = "json"
+	}
+	if opts.verbose && !flagGiven(fs, "log-level") {
+		// -verbose includes the per-draw and timing debug logs
+		if err = setupLogging("debug"); err != nil {
+			return
+		}
+	}
+	collectTimings = opts.repeat > 1
+	if deterministic {
+		opts.concurrency = 1
+	}
+	if opts.concurrency < 1 {
+		err = fmt.Errorf("invalid -concurrency %d: must be at least 1", opts.concurrency)
+	} else if opts.perFile < 0 {
+		err = fmt.Errorf("invalid -boards-per-file %d: must be at least 1", opts.perFile)
+	} else if opts.repeat < 1 {
+		err = fmt.Errorf("invalid -repeat %d: must be at least 1", opts.repeat)
+	} else if opts.sortKey != "index" && opts.sortKey != "score" {
+		err = fmt.Errorf("invalid -sort %q: must be index or score", opts.sortKey)
+	} else if opts.format != "text" && opts.format != "tsv" && opts.format != "json" {
+		err = fmt.Errorf("invalid -format %q: must be text, tsv or json", opts.format)
+	} else if _, ok := lastWinnerTiebreaks[opts.tiebreak]; !ok {
+		err = fmt.Errorf("invalid -last-tiebreak %q: must be highest, lowest or first-index", opts.tiebreak)
+	} else if opts.cache && (opts.format != "text" || opts.summary || opts.quiet) {
+		// the cache only keeps what the plain result lines print
+		err = errors.New("-cache only prints plain text results: -format, -json, -summary and -quiet are not supported")
+	} else if opts.stream && (opts.inputFormat != "text" || opts.boardsDir != "") {
+		// boards are read one at a time from the text of the input file
+		err = errors.New("-stream only reads text input files: -input-format json and -boards-dir are not supported")
+	}
+	lastTiebreak = opts.tiebreak
+	return
+}
+
+// colorEnabled decides whether to emit ANSI colors: -no-color wins over
+// -force-color, which wins over the NO_COLOR convention, and otherwise colors
+// are only used when out is a terminal
+func colorEnabled(noColor, forceColor bool, out *os.File) bool {
+	switch {
+	case noColor:
+		return false
+	case forceColor:
+		return true
+	case os.Getenv("NO_COLOR") != "":
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func runPlay(args []string) error {
+	opts, err := parsePlayArgs(args)
+	if err != nil {
+		return err
+	}
+
+	defer timeit(time.Now(), "main")
+	if opts.replay != "" {
+		fd, err := os.Open(opts.replay)
+		check(err)
+		defer fd.Close()
+		result, err := replayEvents(fd)
+		check(err)
+		fmt.Printf("replay result: %v\n", result)
+		return nil
+	}
+
+	traceDraws = opts.trace != ""
+
+	if opts.compare != "" {
+		compareOpts := opts.inputOptions
+		compareOpts.filenames = []string{opts.filename, opts.compare}
+		results := playFiles(compareOpts, opts.concurrency)
+		for _, result := range results {
+			check(result.err)
+		}
+		if !printComparison(os.Stdout, results[0], results[1]) {
+			return exitStatus(1)
+		}
+		return nil
+	}
+
+	if opts.statsJSON {
+		results := playFiles(opts.inputOptions, opts.concurrency)
+		for _, result := range results {
+			if result.err != nil {
+				slog.Error("cannot play input", "file", result.filename, "err", result.err)
+			}
+		}
+		stats := aggregateResults(results)
+		check(writeStatsJSON(os.Stdout, stats))
+		if stats.Failed > 0 {
+			return exitStatus(1)
+		}
+		return nil
+	}
+
+	if len(opts.filenames) > 1 {
+		failed := false
+		results := playFiles(opts.inputOptions, opts.concurrency)
+		for _, result := range results {
+			if result.err != nil {
+				slog.Error("cannot play input", "file", result.filename, "err", result.err)
+				failed = true
+				continue
+			}
+			if !opts.table {
+				fmt.Printf("%s: part1 result: %v\n", result.filename, result.part1)
+				fmt.Printf("%s: part2 result: %v\n", result.filename, result.part2)
+			}
+		}
+		if opts.table {
+			printResultTable(os.Stdout, results)
+		}
+		if failed {
+			return exitStatus(1)
+		}
+		return nil
+	}
+
+	if opts.cache {
+		result, hit, err := cachedPlay(opts.inputOptions)
+		check(err)
+		slog.Debug("cache", "file", opts.filename, "hit", hit)
+		fmt.Printf("part1 result: %v\n", result.Part1)
+		fmt.Printf("part2 result: %v\n", result.Part2)
+		return nil
+	}
+
+	if opts.boardsFile != "" {
+		boards := loadBoards(opts.inputOptions, opts.boardsFile)
+		check(opts.checkMaxBoards(opts.boardsFile, len(boards)))
+		boards = opts.prepareBoards(boards)
+		return playDrawLines(os.Stdin, os.Stdout, boards, opts.drawsDelim, opts.boardFilter())
+	}
+
+	if opts.stream {
+		result := streamInput(opts.inputOptions)
+		if !result.Won {
+			slog.Warn("no board won", "part", 1)
+		}
+		fmt.Printf("part1 result: %v\n", result)
+		return nil
+	}
+
+	if opts.watch {
+		// the first interrupt stops watching, so it must not end the process
+		ctx, stop := interruptContext(context.Background())
+		defer stop()
+		return watchInput(ctx, opts)
+	}
+	if opts.allocs {
+		report := measureAllocs(func() { err = playInput(context.Background(), opts) })
+		fmt.Fprintln(os.Stderr, report)
+		return err
+	}
+	return playInput(context.Background(), opts)
+}
+
+// playInput plays a single input file and prints the results selected by the
+// options
+func playInput(ctx context.Context, opts playOptions) (err error) {
+	numbers, boards := loadInput(opts.inputOptions)
+	// -target is reported after the results, but an unknown board fails
+	// before anything is played or printed
+	var target GameResult
+	if opts.target >= 0 {
+		target, err = targetWin(boards, numbers, opts.target)
+		if err != nil {
+			check(fmt.Errorf("invalid -target: %w", err))
+		}
+	}
+
+	if opts.dumpDraws {
+		fmt.Println(joinDraws(numbers))
+		return nil
+	}
+
+	if opts.normalize {
+		return writeNormalized(os.Stdout, numbers, boards)
+	}
+
+	if opts.feasible {
+		winnable := winnableBoards(boards, numbers)
+		if len(winnable) == 0 {
+			check(fmt.Errorf("infeasible: no board can win with the %d number draws", len(numbers)))
+		}
+		fmt.Printf("feasible: %d of %d boards can win\n", len(winnable), len(boards))
+		return nil
+	}
+
+	if opts.danger > 0 {
+		n := min(opts.danger, len(numbers))
+		for _, d := range dangerRanking(markFirstN(boards, numbers, n), numbers[n:]) {
+			fmt.Printf("danger after %d draws: %d completes %d lines on %d boards\n", n, d.Number, d.Lines, d.Boards)
+		}
+		return nil
+	}
+
+	if opts.firstN > 0 {
+		stats := firstNDrawsStats(boards, numbers, opts.firstN)
+		fmt.Printf("after %d draws: %d cells marked, %d of %d boards with a completed line\n",
+			stats.draws, stats.markedCells, stats.boardsWithLine, len(boards))
+		return nil
+	}
+
+	if opts.perFile > 0 {
+		printGroupResults(os.Stdout, boards, numbers, opts.perFile)
+		return nil
+	}
+
+	if opts.ndjson {
+		return streamWins(os.Stdout, boards, numbers)
+	}
+
+	if opts.record != "" {
+		check(writeGameLog(opts.record, recordGame(boards, numbers)))
+	}
+
+	if opts.group > 0 {
+		printDrawGroups(os.Stdout, playFullGame(boards, numbers), numbers, opts.group)
+	}
+
+	if opts.verbose {
+		// keep stdout parseable with -format json and tsv
+		progress := os.Stdout
+		if opts.format != "text" {
+			progress = os.Stderr
+		}
+		printProgress(progress, boards, numbers)
+	}
+
+	if opts.order {
+		printWinOrder(os.Stdout, playFullGame(boards, numbers))
+	}
+
+	if opts.ties {
+		for _, pair := range simultaneousWinPairs(boards, numbers) {
+			fmt.Printf("boards %d and %d win on the same draw\n", pair[0], pair[1])
+		}
+	}
+
+	if opts.marks {
+		for draw, marked := range marksPerDraw(boards, numbers) {
+			fmt.Printf("draw #%02d (%d): %d cells marked\n", draw+1, numbers[draw], marked)
+		}
+	}
+
+	if opts.dot != "" {
+		check(writeWinDOT(opts.dot, playFullGame(boards, numbers)))
+	}
+
+	if opts.pivotal {
+		printPivotal(os.Stdout, pivotalNumbers(boards, numbers), numbers)
+	}
+
+	if opts.tournament {
+		printTournament(os.Stdout, tournamentRanking(boards, numbers))
+	}
+
+	if opts.exhaust {
+		if draw, ok := drawsUntilAllWin(boards, numbers); ok {
+			fmt.Printf("exhaust: all %d boards won by draw #%02d\n", len(boards), draw+1)
+		} else {
+			fmt.Printf("exhaust: not all %d boards won within %d draws\n", len(boards), len(numbers))
+		}
+	}
+
+	renderOpts := renderOptions{transpose: opts.transpose, box: opts.box, ascii: opts.ascii,
+		numbered: opts.numbered, tsv: opts.format == "tsv",
+		color: colorEnabled(opts.noColor, opts.forceColor, os.Stdout)}
+
+	if opts.animate || opts.animateAll {
+		if isTerminal(os.Stdout) {
+			animate(os.Stdout, boards, numbers, renderOpts, opts.frameDelay, opts.animateAll)
+		} else {
+			slog.Warn("stdout is not a terminal, not animating")
+		}
+	}
+
+	if opts.fingerprint {
+		fmt.Println(gameFingerprint(boards, numbers))
+		return nil
+	}
+
+	if opts.markedOnly {
+		result := playBingoBestChoice(cloneBoards(boards), numbers)
+		for _, cell := range result.MarkedCells {
+			fmt.Printf("%d,%d\n", cell[0], cell[1])
+		}
+		return nil
+	}
+
+	// only the games stop early on ctx, so an interrupt before them still
+	// ends the process
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+	var result1, result2 GameResult
+	for run := 0; run < opts.repeat; run++ {
+		// each part marks its boards in place, so each starts from a fresh copy
+		part := 1
+		if opts.incremental {
+			result1 = playFirstWinIncremental(boards, numbers)
+		} else {
+			result1, err = playBingoBestChoiceContext(ctx, cloneBoards(boards), numbers)
+		}
+		if err == nil {
+			part = 2
+			// part 1 already paused for the draws up to its win, so part 2
+			// only paces the rest of the game
+			paced := drawDelays
+			if !opts.incremental {
+				waited := len(numbers)
+				if result1.Won {
+					waited = result1.Draw + 1
+				}
+				drawDelays = delaysAfter(paced, waited)
+			}
+			result2, err = playBingoWorstChoiceContext(ctx, cloneBoards(boards), numbers)
+			drawDelays = paced
+		}
+		var interrupted *interruptedError
+		if errors.As(err, &interrupted) {
+			fmt.Printf("part%d interrupted at draw #%02d with %d boards remaining\n",
+				part, interrupted.draws, interrupted.remaining)
+			return exitStatus(130)
+		}
+	}
+	// nothing below checks ctx
+	stop()
+	if opts.repeat > 1 {
+		timings.summarize(os.Stderr)
+	}
+
+	if opts.trace != "" {
+		check(writeTrace(opts.trace, result1, result2))
+	}
+	if opts.png != "" && result1.Won {
+		check(writeBoardPNG(opts.png, result1.Board))
+	}
+
+	if opts.syslog {
+		syslogSummary(result1, result2)
+	}
+	if opts.quiet {
+		// nothing to print
+	} else if opts.summary {
+		fmt.Println(summary(result1, result2))
+	} else if opts.format == "json" {
+		check(writeResultsJSON(os.Stdout, result1, result2))
+	} else {
+		if opts.format == "tsv" {
+			fmt.Println("part\tscore\tnumber\tdraw")
+		}
+		for part, result := range []GameResult{result1, result2} {
+			if !result.Won {
+				slog.Warn("no board won", "part", part+1, "draws", len(numbers))
+			} else if opts.format == "text" && opts.verbose {
+				printBoard(result.Board, renderOpts)
+			}
+			if opts.winners && result.Won {
+				winners, err := sortBoards(result.Winners, opts.sortKey)
+				check(err)
+				for _, b := range winners {
+					fmt.Printf("part%d winning board %d:\n", part+1, b.index)
+					printBoard(b, renderOpts)
+				}
+			}
+			if opts.format == "tsv" {
+				fmt.Println(result.tsv(part + 1))
+				continue
+			}
+			fmt.Printf("part%d result: %v\n", part+1, result)
+			if opts.explain && result.Won {
+				fmt.Printf("part%d %s\n", part+1, result.explain())
+			}
+			if opts.explainWin && result.Won {
+				if narration, ok := narrateWin(result.Board, numbers, result.Draw); ok {
+					fmt.Printf("part%d %s\n", part+1, narration)
+				}
+			}
+		}
+	}
+	if opts.nextWinner && result1.Won {
+		if index, draw, ok := nextWinnerAfter(boards, numbers, result1.Draw); ok {
+			fmt.Printf("next winner: board %d on draw #%02d, number %d\n", index, draw+1, numbers[draw])
+		} else {
+			fmt.Println("next winner: none")
+		}
+	}
+	if opts.without >= 0 {
+		result := replayWithout(boards, numbers, opts.without)
+		if result.Won {
+			fmt.Printf("without board %d: part1 result: %v, board %d on draw #%02d\n",
+				opts.without, result, result.Board.index, result.Draw+1)
+		} else {
+			fmt.Printf("without board %d: part1 result: %v\n", opts.without, result)
+		}
+	}
+	if opts.target >= 0 {
+		if target.Won {
+			fmt.Printf("target board %d: won on draw #%02d, number %d, score %v\n",
+				opts.target, target.Draw+1, target.Number, target)
+		} else {
+			fmt.Printf("target board %d: %v\n", opts.target, target)
+		}
+	}
+	if opts.requireWin {
+		for part, result := range []GameResult{result1, result2} {
+			if !result.Won {
+				check(fmt.Errorf("part%d: no board won within %d draws", part+1, len(numbers)))
+			}
+		}
+	}
+	return nil
+}
+
+func parseValidateArgs(args []string) (opts inputOptions, err error) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	opts.register(fs)
+	err = opts.parse(fs, args)
+	return
+}
+
+// runValidate parses the input without playing and reports what was found
+func runValidate(args []string) error {
+	opts, err := parseValidateArgs(args)
+	if err != nil {
+		return err
+	}
+	numbers, boards := loadInput(opts)
+	if len(boards) == 0 {
+		check(fmt.Errorf("no boards found in %s", opts.filename))
+	}
+	fmt.Printf("%s: ok, %d number draws, %d boards\n", opts.filename, len(numbers), len(boards))
+	return nil
+}
+
+// deterministic makes every run with the same input and options print the
+// same output. It costs the parallelism of playing several inputs at once.
+var deterministic bool
+
+// fixedSeed is the -seed used when none is given in deterministic mode
+const fixedSeed = 1
+
+// defaultSeed returns seed, or for 0 a fixed seed in deterministic mode and
+// one from the current time otherwise
+func defaultSeed(seed int64, deterministic bool) int64 {
+	switch {
+	case seed != 0:
+		return seed
+	case deterministic:
+		return fixedSeed
+	}
+	return time.Now().UnixNano()
+}
+
+type generateOptions struct {
+	seed        int64
+	determinism bool
+	boards      int
+	size        int
+	maxNumber   int
+	answers     string
+}
+
+func parseGenerateArgs(args []string) (opts generateOptions, err error) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	fs.Int64Var(&opts.seed, "seed", 0, "random seed (0 = seed from the current time, or fixed with -deterministic)")
+	fs.BoolVar(&opts.determinism, "deterministic", false, "default -seed to a fixed value so the output is reproducible")
+	fs.IntVar(&opts.boards, "boards", 100, "number of boards to generate")
+	fs.IntVar(&opts.size, "size", boardSize, "rows and columns of each generated board")
+	fs.StringVar(&opts.answers, "generate-with-answers", "", "also write the expected part 1 and part 2 answers as JSON to `FILE`")
+	fs.IntVar(&opts.maxNumber, "max", defaultMaxNumber, "draw numbers from 0 up to (excluding) `N`")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if opts.boards < 1 {
+		err = fmt.Errorf("invalid -boards %d: must be at least 1", opts.boards)
+	} else if opts.size < 1 {
+		err = fmt.Errorf("invalid -size %d: must be at least 1", opts.size)
+	} else if opts.maxNumber < opts.size*opts.size {
+		err = fmt.Errorf("invalid -max %d: boards need at least %d distinct numbers",
+			opts.maxNumber, opts.size*opts.size)
+	}
+	opts.seed = defaultSeed(opts.seed, opts.determinism)
+	return
+}
+
+// runGenerate writes a random puzzle input to stdout
+func runGenerate(args []string) error {
+	opts, err := parseGenerateArgs(args)
+	if err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewSource(opts.seed))
+	var input bytes.Buffer
+	generateInput(&input, rng, opts.boards, opts.maxNumber, opts.size)
+	if opts.answers != "" {
+		check(writeAnswers(opts.answers, solveFixture(input.Bytes())))
+	}
+	_, err = os.Stdout.Write(input.Bytes())
+	return err
+}
+
+type analyzeOptions struct {
+	inputOptions
+	minWin   bool
+	symmetry bool
+	overlap  bool
+	cover    bool
+	anomaly  float64
+	winStats bool
+	colOnly  bool
+	entropy  bool
+	waste    bool
+	expected int
+	seed     int64
+}
+
+func parseAnalyzeArgs(args []string) (opts analyzeOptions, err error) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	opts.register(fs)
+	fs.BoolVar(&opts.minWin, "minwin", false, "print the fewest draws each board needs to win in any order")
+	fs.BoolVar(&opts.symmetry, "symmetry", false, "report boards that are symmetric under transposition, rotation or reflection")
+	fs.BoolVar(&opts.overlap, "overlap-matrix", false, fmt.Sprintf("print the pairwise count of shared numbers (at most %d boards)", maxOverlapBoards))
+	fs.BoolVar(&opts.cover, "min-draws-all-win", false, "print a small set of draws that makes every board win (greedy, not always minimal)")
+	fs.BoolVar(&opts.colOnly, "column-only", false, "list the boards completing a column before any row")
+	fs.BoolVar(&opts.waste, "waste", false, "print how many of each board's cells are marked after it wins, until the part 2 game ends")
+	fs.BoolVar(&opts.entropy, "entropy", false, "print the Shannon entropy of the cell values over all boards, low for repetitive data")
+	fs.BoolVar(&opts.winStats, "winstats", false, "print the mean and variance of the boards' win draw indices")
+	fs.Float64Var(&opts.anomaly, "anomaly", 0, "flag boards whose sum deviates from the median board sum by more than this `FRACTION` of it")
+	fs.IntVar(&opts.expected, "expected", 0, "estimate each board's expected draws to win over `N` random orderings of the draws")
+	fs.Int64Var(&opts.seed, "seed", 0, "random seed for -expected (0 = seed from the current time, or fixed with -deterministic)")
+	if err = opts.parse(fs, args); err != nil {
+		return
+	}
+	if opts.expected < 0 {
+		err = fmt.Errorf("invalid -expected %d: must not be negative", opts.expected)
+	} else if opts.anomaly < 0 {
+		err = fmt.Errorf("invalid -anomaly %g: must not be negative", opts.anomaly)
+	}
+	opts.seed = defaultSeed(opts.seed, deterministic)
+	return
+}
+
+// runAnalyze prints per-board diagnostics without playing the game
+func runAnalyze(args []string) error {
+	opts, err := parseAnalyzeArgs(args)
+	if err != nil {
+		return err
+	}
+	numbers, boards := loadInput(opts.inputOptions)
+	available := map[int]bool{}
+	for _, number := range numbers {
+		available[number] = true
+	}
+	if opts.minWin {
+		for _, b := range boards {
+			fmt.Printf("board %d minwin: %d\n", b.index, minDrawsToWin(b, available))
+		}
+	}
+	if opts.symmetry {
+		for _, b := range boards {
+			if symmetries := boardSymmetries(b); len(symmetries) > 0 {
+				fmt.Printf("board %d symmetry: %s\n", b.index, strings.Join(symmetries, ","))
+			}
+		}
+	}
+	if opts.overlap {
+		check(printOverlapMatrix(os.Stdout, boards))
+	}
+	if opts.colOnly {
+		for _, index := range columnOnlyWinners(boards, numbers) {
+			fmt.Printf("board %d column-only\n", index)
+		}
+	}
+	if opts.winStats {
+		mean, variance, dnf := winIndexStats(boards, numbers)
+		fmt.Printf("winstats: mean %.2f, variance %.2f, stddev %.2f, dnf %d\n", mean, variance, math.Sqrt(variance), dnf)
+	}
+	if opts.waste {
+		for i, wasted := range wastedMarksPerBoard(boards, numbers) {
+			fmt.Printf("board %d waste: %d\n", boards[i].index, wasted)
+		}
+	}
+	if opts.entropy {
+		fmt.Printf("entropy: %.3f bits\n", datasetEntropy(boards))
+	}
+	if opts.anomaly > 0 {
+		for _, a := range sumAnomalies(boards, opts.anomaly) {
+			fmt.Printf("board %d anomaly: sum %d is %+.0f%% off the median\n", a.board, a.sum, a.deviation*100)
+		}
+	}
+	if opts.cover {
+		draws := minDrawsAllWin(boards, numbers)
+		fmt.Printf("min-draws-all-win (%d): %s\n", len(draws), joinDraws(draws))
+	}
+	if opts.expected > 0 {
+		var universe []int
+		for number := range available {
+			universe = append(universe, number)
+		}
+		// map iteration order is random, sort so that -seed is reproducible
+		sort.Ints(universe)
+		rng := rand.New(rand.NewSource(opts.seed))
+		for _, b := range boards {
+			fmt.Printf("board %d expected: %.2f\n", b.index, expectedDrawsToWin(b, universe, opts.expected, rng))
+		}
+	}
+	return nil
+}
+
+// exitStatus is returned by a command that printed its results but must still
+// exit with the given status, e.g. when -compare finds a difference
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+func runCommand(name string, args []string) (err error) {
+	defer recoverFatal(&err)
+	return commands[name](args)
+}
+
+// Main runs the command line tool with the arguments following the program
+// name and returns its exit status: 1 for a fatal error while playing or a
+// failed check such as -compare, 2 for invalid arguments and 130 for an
+// interrupted game. The first argument may name a subcommand, play is the
+// default.
+func Main(args []string) int {
+	name := "play"
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+	if err := runCommand(name, args); err != nil {
+		var fatal fatalError
+		var status exitStatus
+		if errors.As(err, &status) {
+			return int(status)
+		}
+		if errors.As(err, &fatal) {
+			slog.Error("fatal", "err", fatal.err)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
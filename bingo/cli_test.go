@@ -0,0 +1,607 @@
+package bingo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefaultLogLevelSuppressesProgress(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	stdout, stderr, code := runMain(t, input)
+	if code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("default level logged:\n%s", stderr)
+	}
+	if want := "part1 result: 4512\npart2 result: 1924\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+
+	_, stderr, _ = runMain(t, "-log-level", "debug", input)
+	for _, want := range []string{"msg=draw", "msg=duration", `msg="winning board(s) found"`} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("debug level did not log %s:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestDrawCount(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	tests := []struct {
+		count string
+		want  []string
+	}{
+		// part 1 is won on the 12th draw, part 2 on the 15th
+		{"11", []string{"part1 result: no winning board", "part2 result: no winning board"}},
+		{"12", []string{"part1 result: 4512", "part2 result: no winning board"}},
+		{"15", []string{"part1 result: 4512", "part2 result: 1924"}},
+		{"0", []string{"part1 result: 4512", "part2 result: 1924"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.count, func(t *testing.T) {
+			equalLines(t, playLines(t, "-draw-count", tt.count, input), tt.want)
+		})
+	}
+}
+
+func TestRepeatGivesIdenticalResults(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	first := playBingoBestChoice(cloneBoards(boards), numbers)
+	last := playBingoWorstChoice(cloneBoards(boards), numbers)
+	for run := 0; run < 5; run++ {
+		r1 := playBingoBestChoice(cloneBoards(boards), numbers)
+		r2 := playBingoWorstChoice(cloneBoards(boards), numbers)
+		if r1.Score != first.Score || r1.Draw != first.Draw || r1.Board.index != first.Board.index {
+			t.Errorf("run %d: part 1 = %v on draw %d, want %v on draw %d", run, r1, r1.Draw, first, first.Draw)
+		}
+		if r2.Score != last.Score || r2.Draw != last.Draw || r2.Board.index != last.Board.index {
+			t.Errorf("run %d: part 2 = %v on draw %d, want %v on draw %d", run, r2, r2.Draw, last, last.Draw)
+		}
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-repeat", "5", input), playLines(t, input))
+	// the summary is printed at the default log level
+	if _, stderr, _ := runMain(t, "-repeat", "5", input); !strings.Contains(stderr, "timing playBingoBestChoice: ") || !strings.Contains(stderr, " runs, min ") {
+		t.Errorf("-repeat 5 stderr = %q, want the timing summary", stderr)
+	}
+}
+
+// namedSampleInput is sampleInput with the boards labeled Alice, Bob and Carol
+func namedSampleInput() string {
+	blocks := strings.Split(sampleInput, "\n\n")
+	for i, name := range []string{"Alice", "Bob", "Carol"} {
+		blocks[i+1] = "Board: " + name + "\n" + blocks[i+1]
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func TestNamedBoards(t *testing.T) {
+	numbers, boards := parseInput(t, namedSampleInput())
+	for i, name := range []string{"Alice", "Bob", "Carol"} {
+		if boards[i].name != name {
+			t.Errorf("board %d name = %q, want %q", i, boards[i].name, name)
+		}
+	}
+	if result := playBingoBestChoice(cloneBoards(boards), numbers); result.Board.name != "Carol" {
+		t.Errorf("part 1 winner = %q, want Carol", result.Board.name)
+	}
+
+	input := writeFile(t, "input", namedSampleInput())
+	equalLines(t, playLines(t, input), []string{"part1 result: 4512 (Carol)", "part2 result: 1924 (Bob)"})
+}
+
+// sampleDraws and sampleBoards are the two parts of sampleInput
+func sampleDraws() string {
+	draws, _, _ := strings.Cut(sampleInput, "\n")
+	return draws + "\n"
+}
+
+func sampleBoards() string {
+	_, boards, _ := strings.Cut(sampleInput, "\n")
+	return boards
+}
+
+func TestBoardsOnlyFileWithDrawsFile(t *testing.T) {
+	boards := writeFile(t, "boards", sampleBoards())
+	draws := writeFile(t, "draws", sampleDraws())
+	equalLines(t, playLines(t, "-draws", draws, boards), []string{"part1 result: 4512", "part2 result: 1924"})
+	equalLines(t, playLines(t, "-no-draws-line", "-draws", draws, boards), []string{"part1 result: 4512", "part2 result: 1924"})
+
+	_, stderr, code := runMain(t, boards)
+	if code != 1 || !strings.Contains(stderr, "no number draws found") {
+		t.Errorf("board-only file without -draws: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestSubcommandArgs(t *testing.T) {
+	t.Cleanup(saveGlobals())
+	play, err := parsePlayArgs([]string{"-json", "-repeat", "3", "-diagonals", "input"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if play.format != "json" || play.repeat != 3 || !play.win.Diagonals || play.filename != "input" {
+		t.Errorf("play: format %q, repeat %d, diagonals %t, filename %q", play.format, play.repeat, play.win.Diagonals, play.filename)
+	}
+	validate, err := parseValidateArgs([]string{"-size", "3", "boards.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validate.size != 3 || validate.filename != "boards.txt" {
+		t.Errorf("validate: size %d, filename %q", validate.size, validate.filename)
+	}
+	generate, err := parseGenerateArgs([]string{"-deterministic", "-boards", "7", "-size", "4", "-max", "20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generate.seed != fixedSeed || generate.boards != 7 || generate.size != 4 || generate.maxNumber != 20 {
+		t.Errorf("generate: seed %d, boards %d, size %d, max %d", generate.seed, generate.boards, generate.size, generate.maxNumber)
+	}
+	analyze, err := parseAnalyzeArgs([]string{"-minwin", "-expected", "10", "input"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !analyze.minWin || analyze.symmetry || analyze.expected != 10 || analyze.filename != "input" {
+		t.Errorf("analyze: minwin %t, symmetry %t, expected %d, filename %q", analyze.minWin, analyze.symmetry, analyze.expected, analyze.filename)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		parse func() error
+	}{
+		{"play -repeat 0", func() error { _, err := parsePlayArgs([]string{"-repeat", "0"}); return err }},
+		{"validate -lines 0", func() error { _, err := parseValidateArgs([]string{"-lines", "0"}); return err }},
+		{"generate -max 10", func() error { _, err := parseGenerateArgs([]string{"-max", "10"}); return err }},
+		{"analyze -expected -1", func() error { _, err := parseAnalyzeArgs([]string{"-expected", "-1"}); return err }},
+	} {
+		if tt.parse() == nil {
+			t.Errorf("%s: parsed without error", tt.name)
+		}
+	}
+}
+
+func TestSubcommandDispatch(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	results := []string{"part1 result: 4512", "part2 result: 1924"}
+	equalLines(t, playLines(t, input), results)
+	equalLines(t, playLines(t, "play", input), results)
+	equalLines(t, playLines(t, "validate", input), []string{input + ": ok, 27 number draws, 3 boards"})
+	equalLines(t, playLines(t, "analyze", input), []string{""})
+	equalLines(t, playLines(t, "analyze", "-minwin", input), []string{"board 0 minwin: 5", "board 1 minwin: 5", "board 2 minwin: 5"})
+
+	generated := strings.Join(playLines(t, "generate", "-deterministic", "-boards", "2"), "\n")
+	if generated != strings.Join(playLines(t, "generate", "-deterministic", "-boards", "2"), "\n") {
+		t.Error("generate -deterministic printed different inputs")
+	}
+	if _, boards := parseInput(t, generated); len(boards) != 2 {
+		t.Errorf("generate -boards 2 printed %d boards", len(boards))
+	}
+}
+
+// tiedInput has three 2x2 boards that all win on the second draw, with
+// unmarked sums 11, 15 and 19
+const tiedInput = `1,2,3,4
+
+1 2
+5 6
+
+7 8
+1 2
+
+1 9
+2 10
+`
+
+func TestWinnersOrder(t *testing.T) {
+	input := writeFile(t, "input", tiedInput)
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"index", []string{"part1 winning board 0:", "part1 winning board 1:", "part1 winning board 2:"}},
+		{"score", []string{"part1 winning board 2:", "part1 winning board 1:", "part1 winning board 0:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			winners := func() (headers []string) {
+				for _, line := range playLines(t, "-size", "2", "-winners", "-sort", tt.sort, input) {
+					if strings.HasPrefix(line, "part1 winning board") {
+						headers = append(headers, line)
+					}
+				}
+				return headers
+			}
+			first := winners()
+			equalLines(t, first, tt.want)
+			equalLines(t, winners(), first)
+		})
+	}
+}
+
+func TestExplainParse(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	lines := playLines(t, "-explain-parse", input)
+	if len(lines) < 2 || lines[0] != "draws (27): 7,4,9,5,11,17,23,2,0,14,21,24,10,16,13,6,15,25,12,22,18,20,8,19,3,26,1" || lines[1] != "boards (3):" {
+		t.Fatalf("dump starts with %q", lines[:min(2, len(lines))])
+	}
+	dump := strings.Join(lines, "\n")
+	_, boards := parseInput(t, sampleInput)
+	for _, b := range boards {
+		if want := fmt.Sprintf("board %d:\n%s", b.index, b); !strings.Contains(dump, want) {
+			t.Errorf("dump is missing\n%s", want)
+		}
+	}
+	if !strings.HasSuffix(dump, "part1 result: 4512\npart2 result: 1924") {
+		t.Errorf("play did not continue after the dump:\n%s", dump)
+	}
+
+	lines = playLines(t, "validate", "-explain-parse", input)
+	if last := lines[len(lines)-1]; last != input+": ok, 27 number draws, 3 boards" {
+		t.Errorf("validate -explain-parse ends with %q", last)
+	}
+}
+
+func TestMismatchedDelimiters(t *testing.T) {
+	draws, boards, _ := strings.Cut(sampleInput, "\n")
+	var converted []string
+	for _, line := range strings.Split(boards, "\n") {
+		converted = append(converted, strings.Join(strings.Fields(line), ";"))
+	}
+	input := writeFile(t, "input", strings.ReplaceAll(draws, ",", " ")+"\n"+strings.Join(converted, "\n"))
+	results := []string{"part1 result: 4512", "part2 result: 1924"}
+	equalLines(t, playLines(t, "-delim-draws", " ", "-delim", ";", input), results)
+
+	drawsFile := writeFile(t, "draws", strings.ReplaceAll(draws, ",", ";")+"\n")
+	equalLines(t, playLines(t, "-delim-draws", ";", "-draws", drawsFile, writeFile(t, "boards", sampleBoards())), results)
+
+	if _, _, code := runMain(t, "-delim", ";", input); code == 0 {
+		t.Error("space-separated draws parsed with the default -delim-draws")
+	}
+}
+
+func TestRequireWinner(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	tests := []struct {
+		draws string
+		err   string
+	}{
+		{"7,4,9,5,11", "part1: no board won within 5 draws"},
+		// part 1 is won on the 12th draw, part 2 needs 15
+		{"7,4,9,5,11,17,23,2,0,14,21,24", "part2: no board won within 12 draws"},
+	}
+	for _, tt := range tests {
+		_, stderr, code := runMain(t, "-require-winner", "-draws-from-args", tt.draws, input)
+		if code != 1 || !strings.Contains(stderr, tt.err) {
+			t.Errorf("%s: exit status %d, stderr:\n%s\nwant status 1 and %q", tt.draws, code, stderr, tt.err)
+		}
+	}
+	if _, stderr, code := runMain(t, "-require-winner", input); code != 0 {
+		t.Errorf("all draws: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestDrawsFromArgs(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	boards := writeFile(t, "boards", sampleBoards())
+	// the draws up to part 1's win replace the file's draws, with or without a
+	// draws line in the file
+	draws := "7,4,9,5,11,17,23,2,0,14,21,24"
+	for _, file := range []string{input, boards} {
+		equalLines(t, playLines(t, "-summary", "-draws-from-args", draws, file), []string{"part1=4512(n=24,i=11) part2=none"})
+	}
+	equalLines(t, playLines(t, "-dump-draws", "-draws-from-args", "1,2,3", input), []string{"1,2,3"})
+	equalLines(t, playLines(t, "-dump-draws", "-delim-draws", ";", "-draws-from-args", "1;2;3", input), []string{"1,2,3"})
+
+	for _, tt := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-draws-from-args", "7,x"}, `invalid -draws-from-args: invalid number "x" in "7,x"`},
+		{[]string{"-draws-from-args", "7", "-draws", input}, "-draws-from-args, -draws and -timed-draws are mutually exclusive"},
+		{[]string{"-draws-from-args", "7", "-timed-draws", input}, "-draws-from-args, -draws and -timed-draws are mutually exclusive"},
+	} {
+		_, stderr, code := runMain(t, append(tt.args, input)...)
+		if code != 2 || !strings.Contains(stderr, tt.err) {
+			t.Errorf("%v: exit status %d, stderr:\n%s\nwant status 2 and %q", tt.args, code, stderr, tt.err)
+		}
+	}
+}
+
+func TestDrawsEnv(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	drawsFile := writeFile(t, "draws", "4,5,6\n")
+	t.Setenv(drawsEnv, "1,2,3")
+	equalLines(t, playLines(t, "-dump-draws", input), []string{"1,2,3"})
+	equalLines(t, playLines(t, "-dump-draws", "-draws", drawsFile, input), []string{"4,5,6"})
+
+	// the env draws are played instead of the input's
+	numbers, boards := parseInput(t, sampleInput)
+	slices.Reverse(numbers)
+	t.Setenv(drawsEnv, joinDraws(numbers))
+	want := playBingoBestChoice(boards, numbers)
+	if lines := playLines(t, input); lines[0] != fmt.Sprintf("part1 result: %v", want) {
+		t.Errorf("env draws not played: %v", lines)
+	}
+
+	t.Setenv(drawsEnv, "1,x")
+	if _, stderr, code := runMain(t, input); code != 1 || !strings.Contains(stderr, drawsEnv) {
+		t.Errorf("invalid %s: exit status %d, stderr:\n%s", drawsEnv, code, stderr)
+	}
+}
+
+func TestLastTiebreak(t *testing.T) {
+	// all three boards win on the final draw, with scores 30, 22 and 38
+	input := writeFile(t, "input", "dim 2x2\n1,2\n\n7 8\n1 2\n\n1 2\n5 6\n\n1 9\n2 10\n")
+	tests := []struct {
+		tiebreak string
+		want     string
+	}{
+		{"highest", "part2 result: 38"},
+		{"lowest", "part2 result: 22"},
+		{"first-index", "part2 result: 30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tiebreak, func(t *testing.T) {
+			equalLines(t, playLines(t, "-last-tiebreak", tt.tiebreak, input)[1:], []string{tt.want})
+		})
+	}
+	if _, _, code := runMain(t, "-last-tiebreak", "random", input); code != 2 {
+		t.Errorf("-last-tiebreak random: exit status %d, want 2", code)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	// a character device passes for a terminal, a regular file does not
+	tty, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+	if !isTerminal(tty) {
+		t.Skipf("%s is not a character device", os.DevNull)
+	}
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name                string
+		noColorEnv          string
+		noColor, forceColor bool
+		out                 *os.File
+		want                bool
+	}{
+		{"terminal", "", false, false, tty, true},
+		{"piped", "", false, false, file, false},
+		{"NO_COLOR on a terminal", "1", false, false, tty, false},
+		{"-force-color piped", "", false, true, file, true},
+		{"-force-color over NO_COLOR", "1", false, true, tty, true},
+		{"-no-color over -force-color", "", true, true, tty, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColorEnv)
+			if got := colorEnabled(tt.noColor, tt.forceColor, tt.out); got != tt.want {
+				t.Errorf("colorEnabled() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDumpDraws(t *testing.T) {
+	draws, boards, _ := strings.Cut(sampleInput, "\n")
+	tests := []struct {
+		name, input string
+		args        []string
+	}{
+		{"comma-separated", sampleInput, nil},
+		{"space-separated", strings.ReplaceAll(draws, ",", " ") + "\n" + boards, []string{"-delim-draws", " "}},
+		{"semicolons with spaces", strings.ReplaceAll(draws, ",", "; ") + "\n" + boards, []string{"-delim-draws", ";"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(tt.args, "-dump-draws", writeFile(t, "input", tt.input))
+			equalLines(t, playLines(t, args...), []string{draws})
+		})
+	}
+}
+
+func TestMultiplier(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	// the unmarked sums are 188 after 12 draws and 148 after 15, the first
+	// 12 draws add up to 137 and the first 15 to 176
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"last", []string{"part1 result: 4512", "part2 result: 1924"}}, // 188*24, 148*13
+		{"sum-drawn", []string{"part1 result: 25756", "part2 result: 26048"}},
+		{"count-drawn", []string{"part1 result: 2256", "part2 result: 2220"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			equalLines(t, playLines(t, "-multiplier", tt.mode, input), tt.want)
+		})
+	}
+	if _, _, code := runMain(t, "-multiplier", "first", input); code != 2 {
+		t.Errorf("-multiplier first: exit status %d, want 2", code)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-summary", input), []string{"part1=4512(n=24,i=11) part2=1924(n=13,i=14)"})
+
+	if got, want := summary(GameResult{Won: true, Score: 10, Number: 5, Draw: 3}, GameResult{}), "part1=10(n=5,i=3) part2=none"; got != want {
+		t.Errorf("summary() = %q, want %q", got, want)
+	}
+}
+
+func TestDrawsLine(t *testing.T) {
+	boards := strings.TrimPrefix(sampleBoards(), "\n")
+	draws := strings.ReplaceAll(sampleDraws(), ",", " ")
+	n := strings.Count(boards, "\n") + 2
+	input := writeFile(t, "input", boards+"\n"+draws)
+	equalLines(t, playLines(t, "-draws-line", fmt.Sprint(n), input), []string{"part1 result: 4512", "part2 result: 1924"})
+
+	for _, line := range []int{n - 1, n + 1} {
+		_, stderr, code := runMain(t, "-draws-line", fmt.Sprint(line), input)
+		if want := fmt.Sprintf("draws line %d: no number draws found", line); code != 1 || !strings.Contains(stderr, want) {
+			t.Errorf("-draws-line %d: exit status %d, stderr:\n%s", line, code, stderr)
+		}
+	}
+}
+
+func TestDedupeDraws(t *testing.T) {
+	tests := []struct {
+		mode dedupeMode
+		want []int
+	}{
+		{dedupeOff, []int{1, 1, 2, 1, 3, 3}},
+		{dedupeConsecutive, []int{1, 2, 1, 3}},
+		{dedupeAll, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		if got := dedupeDraws([]int{1, 1, 2, 1, 3, 3}, tt.mode); !slices.Equal(got, tt.want) {
+			t.Errorf("dedupeDraws(%q) = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+
+	numbers, _ := parseInput(t, sampleInput)
+	var doubled, twice []string
+	for _, number := range numbers {
+		doubled = append(doubled, fmt.Sprint(number), fmt.Sprint(number))
+		twice = append(twice, fmt.Sprint(number))
+	}
+	twice = append(twice, twice...)
+	want := []string{"part1=4512(n=24,i=11) part2=1924(n=13,i=14)"}
+	for _, tt := range []struct {
+		draws []string
+		flag  string
+	}{
+		{doubled, "-dedupe-draws"},
+		{twice, "-dedupe-draws=all"},
+	} {
+		input := writeFile(t, "input", strings.Join(tt.draws, ",")+"\n"+sampleBoards())
+		equalLines(t, playLines(t, tt.flag, "-summary", input), want)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	if got := defaultSeed(42, true); got != 42 {
+		t.Errorf("defaultSeed(42) = %d, want the given seed", got)
+	}
+	if got := defaultSeed(0, true); got != fixedSeed {
+		t.Errorf("deterministic defaultSeed(0) = %d, want %d", got, fixedSeed)
+	}
+
+	inputs := []string{writeFile(t, "sample", sampleInput)}
+	for i := 0; i < 6; i++ {
+		inputs = append(inputs, writeFile(t, fmt.Sprintf("generated%d", i),
+			strings.Join(playLines(t, "generate", "-seed", fmt.Sprint(i+1), "-boards", "20"), "\n")+"\n"))
+	}
+	for _, args := range [][]string{
+		append([]string{"-deterministic", "-concurrency", "8"}, inputs...),
+		{"analyze", "-deterministic", "-expected", "20", inputs[0]},
+		{"generate", "-deterministic", "-boards", "3"},
+	} {
+		first := strings.Join(playLines(t, args...), "\n")
+		for run := 0; run < 3; run++ {
+			if got := strings.Join(playLines(t, args...), "\n"); got != first {
+				t.Errorf("aoc4 %s: run %d printed\n%s\nwant\n%s", strings.Join(args, " "), run+2, got, first)
+			}
+		}
+	}
+}
+
+func TestZeroIsFree(t *testing.T) {
+	b := parseBoard(t, "1 2 0\n4 0 6\n")
+	if err := (inputOptions{zeroIsFree: true}).prepareBoard(&b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := markedCells(b), [][2]int{{0, 2}, {1, 1}}; !slices.Equal(got, want) {
+		t.Errorf("free cells = %v, want %v", got, want)
+	}
+
+	// row 0 needs the 0, which is never drawn
+	input := writeFile(t, "input", "1,2,5\n\n1 2 0\n4 5 6\n7 8 9\n")
+	equalLines(t, playLines(t, "-size", "3", "-summary", input), []string{"part1=none part2=none"})
+	// (4+5+6+7+8+9) * 2
+	equalLines(t, playLines(t, "-size", "3", "-zero-is-free", "-summary", input), []string{"part1=78(n=2,i=1) part2=78(n=2,i=1)"})
+	equalLines(t, playLines(t, "-size", "3", "-zero-is-free", "-stream", input), []string{"part1 result: 78"})
+}
+
+func TestNormalize(t *testing.T) {
+	canonical := strings.Split(strings.TrimSuffix(sampleInput, "\n"), "\n")
+	equalLines(t, playLines(t, "-normalize", writeFile(t, "input", sampleInput)), canonical)
+
+	// spaces after commas, tabs, padding and runs of blank lines
+	draws, boards, _ := strings.Cut(sampleInput, "\n")
+	var messy strings.Builder
+	messy.WriteString(strings.ReplaceAll(draws, ",", ", ") + "  \n\n\n")
+	for _, row := range strings.Split(strings.TrimSpace(boards), "\n") {
+		if row == "" {
+			messy.WriteString("\n\n")
+			continue
+		}
+		messy.WriteString("  " + strings.Join(strings.Fields(row), " \t") + "  \n")
+	}
+	messy.WriteString("\n\n")
+	equalLines(t, playLines(t, "-normalize", writeFile(t, "messy", messy.String())), canonical)
+
+	equalLines(t, playLines(t, "-normalize", "-size", "2", writeFile(t, "small", "1,2\n\n1 2\n3   4\n")),
+		[]string{"dim 2x2", "1,2", "", " 1  2", " 3  4"})
+}
+
+func TestMaxBoards(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-max-boards", "3", input), []string{"part1 result: 4512", "part2 result: 1924"})
+
+	for _, args := range [][]string{
+		{"-max-boards", "2", input},
+		{"validate", "-max-boards", "1", input},
+	} {
+		_, stderr, code := runMain(t, args...)
+		if want := input + ": more than "; code != 1 || !strings.Contains(stderr, want) {
+			t.Errorf("aoc4 %s: exit status %d, stderr:\n%s", strings.Join(args, " "), code, stderr)
+		}
+	}
+	if _, stderr, code := runMain(t, "-max-boards", "-1", input); code != 2 || !strings.Contains(stderr, "invalid -max-boards -1") {
+		t.Errorf("-max-boards -1: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	stdout, stderr, code := runMain(t, "-verbose", input)
+	if code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	// 12 part 1 progress lines and each part's winning board before its result
+	if len(lines) != 12+5+1+5+1 || !strings.HasPrefix(lines[0], "draw #01 (7): possible lines") ||
+		lines[12] != "[14],[21],[17],[24], [4]" || lines[17] != "part1 result: 4512" || lines[23] != "part2 result: 1924" {
+		t.Errorf("-verbose printed:\n%s", stdout)
+	}
+	for _, want := range []string{"msg=duration func=parseNumberBoards", "msg=draw draw=1 number=7"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("-verbose did not log %s:\n%s", want, stderr)
+		}
+	}
+
+	// progress and boards stay off stdout for machine-readable formats
+	stdout, stderr, _ = runMain(t, "-verbose", "-format", "tsv", input)
+	if want := "part\tscore\tnumber\tdraw\n1\t4512\t24\t11\n2\t1924\t13\t14\n"; stdout != want {
+		t.Errorf("-verbose -format tsv stdout = %q, want %q", stdout, want)
+	}
+	if !strings.Contains(stderr, "draw #12 (24): possible lines") {
+		t.Errorf("-verbose -format tsv did not write progress to stderr:\n%s", stderr)
+	}
+}
@@ -0,0 +1,96 @@
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"unicode/utf16"
+)
+
+// stdinName is the input filename that reads standard input instead
+const stdinName = "-"
+
+// stdin buffers standard input on first use, as a pipe can only be read once
+// but the input may be opened more than once
+var stdin struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// readInputData returns the raw contents of an input file or, for
+// stdinName, of standard input
+func readInputData(filename string) ([]byte, error) {
+	if filename != stdinName {
+		return os.ReadFile(filename)
+	}
+	stdin.once.Do(func() {
+		stdin.data, stdin.err = io.ReadAll(os.Stdin)
+	})
+	return stdin.data, stdin.err
+}
+
+// openInput opens a text input file, or standard input for stdinName, for
+// parsing. Files starting with a UTF-16 byte order mark are decoded to UTF-8,
+// and a UTF-8 byte order mark is dropped; anything else is read as is.
+func openInput(filename string) (io.ReadCloser, error) {
+	if filename == stdinName {
+		data, err := readInputData(filename)
+		if err != nil {
+			return nil, err
+		}
+		r, err := decodeBOM(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}
+	fd, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decodeBOM(fd)
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, fd}, nil
+}
+
+// decodeBOM returns a reader of r's text as UTF-8, based on its byte order
+// mark. UTF-16 input is decoded in memory as a whole.
+func decodeBOM(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	bom, _ := br.Peek(3)
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xef, 0xbb, 0xbf}):
+		br.Discard(3)
+		return br, nil
+	case bytes.HasPrefix(bom, []byte{0xff, 0xfe}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(bom, []byte{0xfe, 0xff}):
+		order = binary.BigEndian
+	default:
+		return br, nil
+	}
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%2 != 0 {
+		return nil, errors.New("truncated UTF-16 input: odd number of bytes")
+	}
+	units := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+	return bytes.NewReader([]byte(string(utf16.Decode(units)))), nil
+}
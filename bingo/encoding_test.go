@@ -0,0 +1,50 @@
+package bingo
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, with a byte order
+// mark
+func encodeUTF16(s string, order binary.ByteOrder) []byte {
+	units := append([]uint16{0xfeff}, utf16.Encode([]rune(s))...)
+	data := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(data[2*i:], unit)
+	}
+	return data
+}
+
+func TestUTF16Input(t *testing.T) {
+	want := playLines(t, writeFile(t, "ascii", sampleInput))
+	for name, data := range map[string][]byte{
+		"utf16le": encodeUTF16(sampleInput, binary.LittleEndian),
+		"utf16be": encodeUTF16(sampleInput, binary.BigEndian),
+		"utf8bom": append([]byte{0xef, 0xbb, 0xbf}, sampleInput...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			equalLines(t, playLines(t, writeFile(t, name, string(data))), want)
+		})
+	}
+
+	truncated := encodeUTF16(sampleInput, binary.LittleEndian)
+	_, stderr, code := runMain(t, writeFile(t, "truncated", string(truncated[:len(truncated)-1])))
+	if code != 1 || !strings.Contains(stderr, "truncated UTF-16 input") {
+		t.Errorf("odd-length UTF-16 input: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestStdinInput(t *testing.T) {
+	want := []string{"part1 result: 4512", "part2 result: 1924"}
+	withStdin(t, sampleInput)
+	equalLines(t, playLines(t, stdinName), want)
+	// standard input is read once and buffered for every later open
+	equalLines(t, playLines(t, "-repeat", "2", stdinName), want)
+	equalLines(t, playLines(t), want)
+
+	withStdin(t, string(encodeUTF16(sampleInput, binary.LittleEndian)))
+	equalLines(t, playLines(t, stdinName), want)
+}
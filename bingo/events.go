@@ -0,0 +1,323 @@
+package bingo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	eventDraw = "draw"
+	eventWin  = "win"
+)
+
+// gameEvent is a single step of a recorded game: either a drawn number or a
+// board completing a line after the preceding draw
+type gameEvent struct {
+	Type   string `json:"type"`
+	Draw   int    `json:"draw"`
+	Number int    `json:"number"`
+	Board  int    `json:"board,omitempty"` // board index, win events only
+	Score  int    `json:"score,omitempty"` // board score, win events only
+}
+
+// gameLog is the exported form of a recorded game: the unmarked boards it
+// started from followed by every event in order
+type gameLog struct {
+	Boards [][][]int   `json:"boards"`
+	Events []gameEvent `json:"events"`
+}
+
+// recordGame plays part 1 and records every draw up to the first win, plus a
+// win event for each board completing a line on that draw
+func recordGame(boards []board, numbers []int) (log gameLog) {
+	boards = cloneBoards(boards)
+	for _, b := range boards {
+		log.Boards = append(log.Boards, b.numbers)
+	}
+	for draw, number := range numbers {
+		boards = markDrawnNumber(boards, number)
+		log.Events = append(log.Events, gameEvent{Type: eventDraw, Draw: draw, Number: number})
+		won := false
+		for i, b := range boards {
+			if boardWon(b) {
+				won = true
+				log.Events = append(log.Events, gameEvent{
+					Type:   eventWin,
+					Draw:   draw,
+					Number: number,
+					Board:  i,
+					Score:  calcBoardScore(b) * multiplier(numbers[:draw+1]),
+				})
+			}
+		}
+		if won {
+			break
+		}
+	}
+	return
+}
+
+func writeGameLog(filename string, log gameLog) error {
+	fd, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	enc := json.NewEncoder(fd)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return err
+	}
+	return fd.Close()
+}
+
+// writeTrace writes the per-draw durations of both parts as JSON, in
+// nanoseconds
+func writeTrace(filename string, part1, part2 GameResult) error {
+	fd, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	trace := struct {
+		Part1 []time.Duration `json:"part1"`
+		Part2 []time.Duration `json:"part2"`
+	}{part1.Trace, part2.Trace}
+	if err := json.NewEncoder(fd).Encode(trace); err != nil {
+		return err
+	}
+	return fd.Close()
+}
+
+// partJSON is the -format json form of a part's result. Trace holds the
+// per-draw durations in nanoseconds with -trace.
+type partJSON struct {
+	Score      int             `json:"score"`
+	Number     int             `json:"number"`
+	DrawIndex  int             `json:"drawIndex"`
+	BoardIndex int             `json:"boardIndex"`
+	Board      [][]int         `json:"board"`
+	Trace      []time.Duration `json:"trace,omitempty"`
+}
+
+// writeResultsJSON writes both parts' results as a single JSON object, with
+// null for a part without a winner
+func writeResultsJSON(w io.Writer, part1, part2 GameResult) error {
+	toJSON := func(r GameResult) *partJSON {
+		if !r.Won {
+			return nil
+		}
+		return &partJSON{r.Score, r.Number, r.Draw, r.Board.index, r.Board.numbers, r.Trace}
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Part1 *partJSON `json:"part1"`
+		Part2 *partJSON `json:"part2"`
+	}{toJSON(part1), toJSON(part2)})
+}
+
+// winEvent is the NDJSON form of a board winning in a full game
+type winEvent struct {
+	Board     int `json:"board"`
+	DrawIndex int `json:"drawIndex"`
+	Number    int `json:"number"`
+	Score     int `json:"score"`
+}
+
+// streamWins plays a full game and writes one JSON object per line to w as
+// each board wins, so a consumer sees the events while the game runs
+func streamWins(w io.Writer, boards []board, numbers []int) (err error) {
+	enc := json.NewEncoder(w)
+	playFullGameFunc(boards, numbers, func(win boardWin) {
+		if err == nil {
+			err = enc.Encode(winEvent{win.board, win.draw, win.killShot, win.score})
+		}
+	})
+	return
+}
+
+// Options configures the games of the exported API. The zero value plays by
+// the puzzle's rules.
+type Options struct {
+	Win          WinOptions      // zero value: the default rows and columns
+	Multiplier   string          // key of scoreMultipliers, empty for "last"
+	ScoreMarked  bool            // score the marked numbers instead of the unmarked ones
+	LastTiebreak string          // key of lastWinnerTiebreaks for part 2, empty for "highest"
+	Context      context.Context // stops a PlayStream game early once canceled, nil for never
+}
+
+// rules returns the rules selected by the options, with the defaults filled
+// in for unset and unknown settings
+func (o Options) rules() rules {
+	r := rules{win: o.Win, multiplier: o.Multiplier, scoreMarked: o.ScoreMarked, tiebreak: o.LastTiebreak}
+	if r.win == (WinOptions{}) {
+		r.win = defaultWinOptions
+	}
+	r.win.Lines = max(r.win.Lines, 1)
+	if _, ok := scoreMultipliers[r.multiplier]; !ok {
+		r.multiplier = "last"
+	}
+	if _, ok := lastWinnerTiebreaks[r.tiebreak]; !ok {
+		r.tiebreak = "highest"
+	}
+	return r
+}
+
+// Event is a step of a game streamed by PlayStream: a number marked on every
+// board, or a board completing its win pattern on the preceding mark
+type Event struct {
+	Type   string `json:"type"` // eventMark or eventWin
+	Draw   int    `json:"draw"`
+	Number int    `json:"number"`
+	Board  int    `json:"board,omitempty"` // board index, win events only
+	Score  int    `json:"score,omitempty"` // board score, win events only
+}
+
+// eventMark is the Type of an Event marking a drawn number
+const eventMark = "mark"
+
+// PlayStream plays a full game in a new goroutine and sends a mark event for
+// every draw and a win event for every board as it wins. The channel is
+// closed once every board has won, the draws run out or opts.Context is
+// canceled. Callers that stop receiving early must cancel opts.Context so
+// the goroutine can exit.
+func PlayStream(boards []board, numbers []int, opts Options) <-chan Event {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := opts.rules()
+	boards = cloneBoards(boards)
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		send := func(event Event) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		won := make([]bool, len(boards))
+		remaining := len(boards)
+		for draw, number := range numbers {
+			if remaining == 0 {
+				return
+			}
+			boards = markDrawnNumber(boards, number)
+			if !send(Event{Type: eventMark, Draw: draw, Number: number}) {
+				return
+			}
+			for i, b := range boards {
+				if won[i] || !r.boardWon(b) {
+					continue
+				}
+				won[i] = true
+				remaining--
+				score := r.scoreWith(b, nil) * r.factor(numbers[:draw+1])
+				if !send(Event{Type: eventWin, Draw: draw, Number: number, Board: b.index, Score: score}) {
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// replayEvents reads a recorded game, re-applies its draws to the recorded
+// boards and recomputes the part 1 result, checking that every win event is
+// justified by the marks and that no win went unreported
+func replayEvents(r io.Reader) (result GameResult, err error) {
+	var log gameLog
+	if err = json.NewDecoder(r).Decode(&log); err != nil {
+		return result, fmt.Errorf("decoding event log: %w", err)
+	}
+	boards := make([]board, len(log.Boards))
+	for i, numbers := range log.Boards {
+		if boards[i], err = newBoardFromNumbers(numbers); err != nil {
+			return result, fmt.Errorf("board %d: %w", i, err)
+		}
+		boards[i].index = i
+		if i > 0 && boards[i].dimensions() != boards[0].dimensions() {
+			return result, fmt.Errorf("board %d is %v, expected %v", i, boards[i].dimensions(), boards[0].dimensions())
+		}
+	}
+
+	var winners []board
+	var drawn []int
+	var reported map[int]bool
+	lastDraw, lastNumber := -1, 0
+	// checkReported makes sure every board that won on the last draw had a
+	// matching win event
+	checkReported := func() error {
+		for i, b := range boards {
+			if boardWon(b) && !reported[i] {
+				return fmt.Errorf("board %d won on draw %d without a win event", i, lastDraw)
+			}
+		}
+		return nil
+	}
+
+	for n, event := range log.Events {
+		switch event.Type {
+		case eventDraw:
+			if len(winners) > 0 {
+				return result, fmt.Errorf("event %d: draw after the game was won", n)
+			}
+			if err = checkReported(); err != nil {
+				return result, err
+			}
+			if event.Draw != lastDraw+1 {
+				return result, fmt.Errorf("event %d: expected draw %d, got %d", n, lastDraw+1, event.Draw)
+			}
+			boards = markDrawnNumber(boards, event.Number)
+			drawn = append(drawn, event.Number)
+			reported = map[int]bool{}
+			lastDraw, lastNumber = event.Draw, event.Number
+		case eventWin:
+			if event.Board < 0 || event.Board >= len(boards) {
+				return result, fmt.Errorf("event %d: board %d out of range", n, event.Board)
+			}
+			if event.Draw != lastDraw || event.Number != lastNumber {
+				return result, fmt.Errorf("event %d: win does not follow draw %d", n, event.Draw)
+			}
+			b := boards[event.Board]
+			if !boardWon(b) {
+				return result, fmt.Errorf("event %d: board %d has no completed line on draw %d",
+					n, event.Board, event.Draw)
+			}
+			if score := calcBoardScore(b) * multiplier(drawn); score != event.Score {
+				return result, fmt.Errorf("event %d: board %d score is %d, event claims %d",
+					n, event.Board, score, event.Score)
+			}
+			reported[event.Board] = true
+			winners = append(winners, b)
+		default:
+			return result, fmt.Errorf("event %d: unknown event type %q", n, event.Type)
+		}
+	}
+	if err = checkReported(); err != nil {
+		return result, err
+	}
+	if len(winners) == 0 {
+		return result, errors.New("event log contains no win")
+	}
+
+	best := findHighestScoringBoard(winners)
+	sum := calcBoardScore(best)
+	factor := multiplier(drawn)
+	return GameResult{
+		Won:        true,
+		Score:      sum * factor,
+		Multiplier: factor,
+		Sum:        sum,
+		Draw:       lastDraw,
+		Number:     lastNumber,
+		Board:      best,
+	}, nil
+}
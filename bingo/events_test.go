@@ -0,0 +1,220 @@
+package bingo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayEventsRoundTrip(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(recordGame(boards, numbers)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := replayEvents(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := playBingoBestChoice(cloneBoards(boards), numbers)
+	if got.Score != want.Score || got.Draw != want.Draw || got.Number != want.Number || got.Board.index != want.Board.index {
+		t.Errorf("replayed %v on draw %d (number %d, board %d), played %v on draw %d (number %d, board %d)",
+			got, got.Draw, got.Number, got.Board.index, want, want.Draw, want.Number, want.Board.index)
+	}
+}
+
+func TestReplayEventsInconsistent(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	tests := []struct {
+		name   string
+		tamper func(log *gameLog)
+	}{
+		{"win by the wrong board", func(log *gameLog) { log.Events[len(log.Events)-1].Board = 0 }},
+		{"wrong score", func(log *gameLog) { log.Events[len(log.Events)-1].Score++ }},
+		{"missing draw", func(log *gameLog) { log.Events = log.Events[1:] }},
+		{"missing win", func(log *gameLog) { log.Events = log.Events[:len(log.Events)-1] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := recordGame(boards, numbers)
+			tt.tamper(&log)
+			data, err := json.Marshal(log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result, err := replayEvents(bytes.NewReader(data)); err == nil {
+				t.Errorf("replayed tampered log as %v", result)
+			}
+		})
+	}
+}
+
+func TestTraceLength(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	traceFile := filepath.Join(t.TempDir(), "trace.json")
+	stdout, stderr, code := runMain(t, "-json", "-trace", traceFile, input)
+	if code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+	}
+	var results struct{ Part1, Part2 partJSON }
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace struct{ Part1, Part2 []time.Duration }
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+	// part 1 stops on its 12th draw, part 2 on its 15th
+	for _, tt := range []struct {
+		name  string
+		trace []time.Duration
+		want  int
+	}{
+		{"part1 json", results.Part1.Trace, 12},
+		{"part2 json", results.Part2.Trace, 15},
+		{"part1 file", trace.Part1, 12},
+		{"part2 file", trace.Part2, 15},
+	} {
+		if len(tt.trace) != tt.want {
+			t.Errorf("%s: %d durations, want %d", tt.name, len(tt.trace), tt.want)
+		}
+	}
+
+	stdout, _, _ = runMain(t, "-json", input)
+	if strings.Contains(stdout, `"trace"`) {
+		t.Errorf("trace in the output without -trace:\n%s", stdout)
+	}
+}
+
+func TestStreamWins(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// with only the first 14 draws board 1 never wins
+	for _, tt := range []struct {
+		draws int
+		want  []winEvent
+	}{
+		{len(numbers), []winEvent{{2, 11, 24, 4512}, {0, 13, 16, 2192}, {1, 14, 13, 1924}}},
+		{14, []winEvent{{2, 11, 24, 4512}, {0, 13, 16, 2192}}},
+	} {
+		var buf bytes.Buffer
+		if err := streamWins(&buf, cloneBoards(boards), numbers[:tt.draws]); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != len(tt.want) {
+			t.Fatalf("%d draws: %d lines, want one per winning board:\n%s", tt.draws, len(lines), buf.String())
+		}
+		for i, line := range lines {
+			var event winEvent
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				t.Fatal(err)
+			}
+			if event != tt.want[i] {
+				t.Errorf("%d draws, line %d = %+v, want %+v", tt.draws, i, event, tt.want[i])
+			}
+		}
+	}
+
+	lines := playLines(t, "-ndjson", writeFile(t, "input", sampleInput))
+	equalLines(t, lines, []string{
+		`{"board":2,"drawIndex":11,"number":24,"score":4512}`,
+		`{"board":0,"drawIndex":13,"number":16,"score":2192}`,
+		`{"board":1,"drawIndex":14,"number":13,"score":1924}`,
+	})
+}
+
+func TestPlayStream(t *testing.T) {
+	boards := []board{parseBoard(t, "1 2\n3 4\n"), parseBoard(t, "1 3\n5 6\n")}
+	boards[1].index = 1
+	var got []Event
+	for event := range PlayStream(boards, []int{1, 2, 3, 4}, Options{}) {
+		got = append(got, event)
+	}
+	// no mark event follows the last win
+	want := []Event{
+		{Type: eventMark, Draw: 0, Number: 1},
+		{Type: eventMark, Draw: 1, Number: 2},
+		{Type: eventWin, Draw: 1, Number: 2, Board: 0, Score: 14},
+		{Type: eventMark, Draw: 2, Number: 3},
+		{Type: eventWin, Draw: 2, Number: 3, Board: 1, Score: 33},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("PlayStream() sent %+v, want %+v", got, want)
+	}
+	if _, marked := boards[0].At(0, 0); marked {
+		t.Error("PlayStream marked the boards it was given")
+	}
+
+	// sum-drawn: (3+4) * (1+2)
+	events := PlayStream(boards[:1], []int{1, 2}, Options{Multiplier: "sum-drawn"})
+	<-events
+	<-events
+	if win := <-events; win.Type != eventWin || win.Score != 21 {
+		t.Errorf("sum-drawn win = %+v, want score 21", win)
+	}
+
+	// no board holds the draws, so only canceling ends the game early
+	draws := make([]int, 1000)
+	for i := range draws {
+		draws[i] = 100 + i
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	events = PlayStream(boards, draws, Options{Context: ctx})
+	<-events
+	cancel()
+	received := 1
+	for range events {
+		received++
+	}
+	if received == len(draws) {
+		t.Errorf("PlayStream sent all %d draws after its context was canceled", received)
+	}
+}
+
+func TestResultsJSON(t *testing.T) {
+	type part struct {
+		Score      int     `json:"score"`
+		Number     int     `json:"number"`
+		DrawIndex  int     `json:"drawIndex"`
+		BoardIndex int     `json:"boardIndex"`
+		Board      [][]int `json:"board"`
+	}
+	var results struct {
+		Part1, Part2 *part
+	}
+	input := writeFile(t, "input", sampleInput)
+	lines := playLines(t, "-json", input)
+	if len(lines) != 1 {
+		t.Fatalf("-json printed %d lines, want one object", len(lines))
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &results); err != nil {
+		t.Fatal(err)
+	}
+	_, boards := parseInput(t, sampleInput)
+	want1 := part{4512, 24, 11, 2, boards[2].numbers}
+	want2 := part{1924, 13, 14, 1, boards[1].numbers}
+	if results.Part1 == nil || !reflect.DeepEqual(*results.Part1, want1) {
+		t.Errorf("part1 = %+v, want %+v", results.Part1, want1)
+	}
+	if results.Part2 == nil || !reflect.DeepEqual(*results.Part2, want2) {
+		t.Errorf("part2 = %+v, want %+v", results.Part2, want2)
+	}
+	equalLines(t, playLines(t, "-format", "json", input), lines)
+
+	// a part without a winner is null
+	lines = playLines(t, "-json", "-draw-count", "12", input)
+	if !strings.HasSuffix(lines[0], `"part2":null}`) || !strings.HasPrefix(lines[0], `{"part1":{"score":4512,`) {
+		t.Errorf("-json after 12 draws printed %s", lines[0])
+	}
+}
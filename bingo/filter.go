@@ -0,0 +1,79 @@
+package bingo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// boardPredicate decides whether a board is kept by -filter, given the
+// number draws of the game
+type boardPredicate func(b board, numbers []int) bool
+
+// boardPredicates maps each -filter predicate name to a constructor taking
+// its argument, which is empty for predicates without one
+var boardPredicates = map[string]func(arg string) (boardPredicate, error){
+	"contains": func(arg string) (boardPredicate, error) {
+		value, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(b board, _ []int) bool {
+			_, _, ok := b.find(value)
+			return ok
+		}, nil
+	},
+	"sum-gt": func(arg string) (boardPredicate, error) {
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(b board, _ []int) bool { return boardSum(b) > limit }, nil
+	},
+	"has-line-drawn": func(arg string) (boardPredicate, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("takes no argument, got %q", arg)
+		}
+		return func(b board, numbers []int) bool {
+			return len(winnableBoards([]board{b}, numbers)) > 0
+		}, nil
+	},
+}
+
+// parseBoardFilter parses a -filter expression: a predicate such as
+// "contains:42", "sum-gt:300" or "has-line-drawn". Several predicates joined
+// by "," must all hold.
+func parseBoardFilter(expr string) (boardPredicate, error) {
+	var predicates []boardPredicate
+	for _, term := range strings.Split(expr, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(term), ":")
+		newPredicate, ok := boardPredicates[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q: must be contains:N, sum-gt:N or has-line-drawn", name)
+		}
+		predicate, err := newPredicate(arg)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", name, err)
+		}
+		predicates = append(predicates, predicate)
+	}
+	return func(b board, numbers []int) bool {
+		for _, predicate := range predicates {
+			if !predicate(b, numbers) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// filterBoards keeps the boards matching the predicate, preserving their
+// relative order and original indices
+func filterBoards(boards []board, numbers []int, keep boardPredicate) (kept []board) {
+	for _, b := range boards {
+		if keep(b, numbers) {
+			kept = append(kept, b)
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package bingo
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBoardFilter(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	tests := []struct {
+		expr    string
+		numbers []int
+		want    []int
+	}{
+		{"contains:26", numbers, []int{2}},
+		{"contains:1", numbers, []int{0}},
+		{"contains:99", numbers, nil},
+		{"sum-gt:300", numbers, []int{1, 2}}, // sums 300, 324 and 325
+		{"sum-gt:324", numbers, []int{2}},
+		{"has-line-drawn", numbers, []int{0, 1, 2}},
+		{"has-line-drawn", numbers[:12], []int{2}}, // only board 2 wins by draw 12
+		{"contains:22, sum-gt:310", numbers, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		keep, err := parseBoardFilter(tt.expr)
+		if err != nil {
+			t.Errorf("parseBoardFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		var got []int
+		for _, b := range filterBoards(boards, tt.numbers, keep) {
+			got = append(got, b.index)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("-filter %s with %d draws kept boards %v, want %v", tt.expr, len(tt.numbers), got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ expr, err string }{
+		{"odd", `unknown filter "odd"`},
+		{"contains:x", "filter contains:"},
+		{"sum-gt:", "filter sum-gt:"},
+		{"has-line-drawn:1", `filter has-line-drawn: takes no argument, got "1"`},
+	} {
+		if _, err := parseBoardFilter(tt.expr); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseBoardFilter(%q) error = %v, want %q", tt.expr, err, tt.err)
+		}
+	}
+
+	input := writeFile(t, "input", sampleInput)
+	equalLines(t, playLines(t, "-filter", "contains:1", "-summary", input), []string{"part1=2192(n=16,i=13) part2=2192(n=16,i=13)"})
+}
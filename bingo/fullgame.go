@@ -0,0 +1,265 @@
+package bingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// boardWin records when a board first completed a line in a full game
+type boardWin struct {
+	board    int // index of the board in the input
+	won      bool
+	draw     int // index of the winning draw
+	killShot int // number that completed the board's first line
+	score    int
+}
+
+// playFullGame keeps drawing until every board has won or the numbers run
+// out, and returns a win record for every board in input order
+func playFullGame(boards []board, numbers []int) []boardWin {
+	return playFullGameFunc(boards, numbers, nil)
+}
+
+// playFullGameFunc is playFullGame that also calls onWin, if not nil, as soon
+// as each board wins
+func playFullGameFunc(boards []board, numbers []int, onWin func(boardWin)) []boardWin {
+	wins := make([]boardWin, len(boards))
+	for i, b := range boards {
+		wins[i] = boardWin{board: b.index, draw: -1}
+	}
+	boards = cloneBoards(boards)
+	remaining := len(boards)
+	for draw, number := range numbers {
+		if remaining == 0 {
+			break
+		}
+		boards = markDrawnNumber(boards, number)
+		for i, b := range boards {
+			if !wins[i].won && boardWon(b) {
+				wins[i] = boardWin{
+					board:    b.index,
+					won:      true,
+					draw:     draw,
+					killShot: number,
+					score:    calcBoardScore(b) * multiplier(numbers[:draw+1]),
+				}
+				remaining--
+				if onWin != nil {
+					onWin(wins[i])
+				}
+			}
+		}
+	}
+	return wins
+}
+
+// pivotalNumbers maps every number that completed a board's first line in a
+// full game to how many boards it made win
+func pivotalNumbers(boards []board, numbers []int) map[int]int {
+	pivotal := map[int]int{}
+	for _, win := range playFullGame(boards, numbers) {
+		if win.won {
+			pivotal[win.killShot]++
+		}
+	}
+	return pivotal
+}
+
+// printPivotal writes a line per pivotal number in draw order with how many
+// boards it made win
+func printPivotal(w io.Writer, pivotal map[int]int, numbers []int) {
+	printed := map[int]bool{}
+	for draw, number := range numbers {
+		// a repeated draw cannot make any more boards win
+		if count, ok := pivotal[number]; ok && !printed[number] {
+			fmt.Fprintf(w, "draw #%02d (%d): %d boards won\n", draw+1, number, count)
+			printed[number] = true
+		}
+	}
+}
+
+// simultaneousWinPairs returns every pair of board indices, in input order,
+// whose first line completes on the same draw of a full game
+func simultaneousWinPairs(boards []board, numbers []int) (pairs [][2]int) {
+	wins := playFullGame(boards, numbers)
+	for i, a := range wins {
+		for _, b := range wins[i+1:] {
+			if a.won && b.won && a.draw == b.draw {
+				pairs = append(pairs, [2]int{a.board, b.board})
+			}
+		}
+	}
+	return
+}
+
+// gameFingerprint returns a SHA-256 hex digest of both parts' outcome: each
+// part's score, winning number, draw index and the checksum of the winning
+// board. Any change to the results of a fixed input changes the fingerprint.
+func gameFingerprint(boards []board, numbers []int) string {
+	results := []GameResult{
+		playBingoBestChoice(cloneBoards(boards), numbers),
+		playBingoWorstChoice(cloneBoards(boards), numbers),
+	}
+	h := sha256.New()
+	for part, r := range results {
+		if !r.Won {
+			fmt.Fprintf(h, "part%d=none;", part+1)
+			continue
+		}
+		fmt.Fprintf(h, "part%d=%d,%d,%d,%016x;", part+1, r.Score, r.Number, r.Draw, r.Board.checksum())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeWinDOT writes a Graphviz graph of a full game: a node per board,
+// labelled with its place in the win order, and an edge between every two
+// boards won by the same kill shot number. Boards that never won are dashed.
+func writeWinDOT(filename string, wins []boardWin) error {
+	ordered := append([]boardWin(nil), wins...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].won != ordered[j].won {
+			return ordered[i].won
+		}
+		return ordered[i].draw < ordered[j].draw
+	})
+	var sb strings.Builder
+	sb.WriteString("graph wins {\n")
+	for place, win := range ordered {
+		if !win.won {
+			fmt.Fprintf(&sb, "  b%d [label=\"board %d\\nno win\", style=dashed];\n", win.board, win.board)
+			continue
+		}
+		fmt.Fprintf(&sb, "  b%d [label=\"board %d\\n#%d, draw %d\"];\n", win.board, win.board, place+1, win.draw+1)
+	}
+	for i, a := range wins {
+		for _, b := range wins[i+1:] {
+			if a.won && b.won && a.killShot == b.killShot {
+				fmt.Fprintf(&sb, "  b%d -- b%d [label=\"%d\"];\n", a.board, b.board, a.killShot)
+			}
+		}
+	}
+	sb.WriteString("}\n")
+	return os.WriteFile(filename, []byte(sb.String()), 0o644)
+}
+
+// printDrawGroups writes a line after every groupSize draws with the numbers
+// drawn in that group and how many boards have won so far
+func printDrawGroups(w io.Writer, wins []boardWin, numbers []int, groupSize int) {
+	for start := 0; start < len(numbers); start += groupSize {
+		end := start + groupSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		won := 0
+		for _, win := range wins {
+			if win.won && win.draw < end {
+				won++
+			}
+		}
+		group := make([]string, 0, end-start)
+		for _, number := range numbers[start:end] {
+			group = append(group, fmt.Sprint(number))
+		}
+		fmt.Fprintf(w, "draws #%02d-#%02d [%s]: %d of %d boards won\n",
+			start+1, end, strings.Join(group, " "), won, len(wins))
+	}
+}
+
+// printWinOrder writes one line per board in the order the boards won, with
+// the draw and kill shot number that completed each, followed by the boards
+// that never won
+func printWinOrder(w io.Writer, wins []boardWin) {
+	ordered := append([]boardWin(nil), wins...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].won != ordered[j].won {
+			return ordered[i].won
+		}
+		return ordered[i].draw < ordered[j].draw
+	})
+	for _, win := range ordered {
+		if !win.won {
+			fmt.Fprintf(w, "board %d: no win\n", win.board)
+			continue
+		}
+		fmt.Fprintf(w, "board %d: won on draw #%02d, kill shot %d, score %d\n",
+			win.board, win.draw+1, win.killShot, win.score)
+	}
+}
+
+// printProgress writes a line per draw of part 1, up to and including the
+// winning draw, with how many win patterns the boards can still complete
+// using the numbers that have not been drawn yet
+func printProgress(w io.Writer, boards []board, numbers []int) {
+	boards = cloneBoards(boards)
+	for draw, number := range numbers {
+		boards = markDrawnNumber(boards, number)
+		remaining := map[int]bool{}
+		for _, n := range numbers[draw+1:] {
+			remaining[n] = true
+		}
+		// without boards every count is 0
+		lowest, highest, total := 0, 0, 0
+		for i, b := range boards {
+			possible := remainingPossibleLines(b, remaining)
+			if i == 0 || possible < lowest {
+				lowest = possible
+			}
+			highest = max(highest, possible)
+			total += possible
+		}
+		fmt.Fprintf(w, "draw #%02d (%d): possible lines min %d, max %d, total %d\n",
+			draw+1, number, lowest, highest, total)
+		if len(findWinningBoards(boards)) > 0 {
+			break
+		}
+	}
+}
+
+// BoardRank is one entry of a tournament leaderboard
+type BoardRank struct {
+	Rank     int // 1 is best; boards winning on the same draw share a rank
+	Index    int // index of the board in the input
+	DNF      bool
+	Draw     int // index of the winning draw, -1 for DNF
+	KillShot int
+	Score    int
+}
+
+// tournamentRanking plays a full game and ranks every board by the draw it
+// won on, earliest first. Boards that never won come last as DNF, in input
+// order.
+func tournamentRanking(boards []board, numbers []int) []BoardRank {
+	wins := playFullGame(boards, numbers)
+	sort.SliceStable(wins, func(i, j int) bool {
+		if wins[i].won != wins[j].won {
+			return wins[i].won
+		}
+		return wins[i].draw < wins[j].draw
+	})
+	ranks := make([]BoardRank, len(wins))
+	for i, win := range wins {
+		ranks[i] = BoardRank{Rank: i + 1, Index: win.board, DNF: !win.won, Draw: win.draw,
+			KillShot: win.killShot, Score: win.score}
+		if i > 0 && wins[i-1].won == win.won && wins[i-1].draw == win.draw {
+			ranks[i].Rank = ranks[i-1].Rank
+		}
+	}
+	return ranks
+}
+
+// printTournament writes the leaderboard as an aligned table
+func printTournament(w io.Writer, ranks []BoardRank) {
+	fmt.Fprintf(w, "%4s %5s %5s %5s %6s\n", "rank", "board", "draw", "kill", "score")
+	for _, r := range ranks {
+		if r.DNF {
+			fmt.Fprintf(w, "%4s %5d %5s %5s %6s\n", "DNF", r.Index, "-", "-", "-")
+			continue
+		}
+		fmt.Fprintf(w, "%4d %5d %5d %5d %6d\n", r.Rank, r.Index, r.Draw+1, r.KillShot, r.Score)
+	}
+}
@@ -0,0 +1,213 @@
+package bingo
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrintDrawGroups(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	wins := playFullGame(boards, numbers)
+	// the boards win on draws #12, #14 and #15
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{4, []int{0, 0, 1, 3, 3, 3, 3}},
+		{14, []int{2, 3}},
+		{27, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.size), func(t *testing.T) {
+			var out strings.Builder
+			printDrawGroups(&out, wins, numbers, tt.size)
+			var counts []int
+			for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+				var won, total int
+				_, summary, _ := strings.Cut(line, "]: ")
+				if _, err := fmt.Sscanf(summary, "%d of %d boards won", &won, &total); err != nil || total != 3 {
+					t.Fatalf("unexpected line %q", line)
+				}
+				counts = append(counts, won)
+			}
+			if !slices.Equal(counts, tt.want) {
+				t.Errorf("cumulative winners %v, want %v", counts, tt.want)
+			}
+		})
+	}
+
+	var out strings.Builder
+	printDrawGroups(&out, wins, numbers, 10)
+	if want := "draws #21-#27 [18 20 8 19 3 26 1]: 3 of 3 boards won\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("last group is not %q:\n%s", want, out.String())
+	}
+}
+
+func TestKillShots(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// the boards win on staggered draws, one at a time
+	want := []boardWin{
+		{board: 0, won: true, draw: 13, killShot: 16, score: 2192},
+		{board: 1, won: true, draw: 14, killShot: 13, score: 1924},
+		{board: 2, won: true, draw: 11, killShot: 24, score: 4512},
+	}
+	if got := playFullGame(boards, numbers); !slices.Equal(got, want) {
+		t.Errorf("playFullGame() = %+v, want %+v", got, want)
+	}
+	// board 1 never wins without its kill shot 13
+	if got := playFullGame(boards, numbers[:14]); got[1].won {
+		t.Errorf("board 1 won on draw %d without its kill shot", got[1].draw)
+	}
+
+	lines := playLines(t, "-order", writeFile(t, "input", sampleInput))
+	equalLines(t, lines[:3], []string{
+		"board 2: won on draw #12, kill shot 24, score 4512",
+		"board 0: won on draw #14, kill shot 16, score 2192",
+		"board 1: won on draw #15, kill shot 13, score 1924",
+	})
+}
+
+func TestTournamentRanking(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// board 1 needs the 15th draw, so it does not finish in 14
+	got := tournamentRanking(boards, numbers[:14])
+	want := []BoardRank{
+		{Rank: 1, Index: 2, Draw: 11, KillShot: 24, Score: 4512},
+		{Rank: 2, Index: 0, Draw: 13, KillShot: 16, Score: 2192},
+		{Rank: 3, Index: 1, DNF: true, Draw: -1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tournamentRanking() = %+v, want %+v", got, want)
+	}
+
+	var out strings.Builder
+	printTournament(&out, got)
+	if want := "rank board  draw  kill  score\n   1     2    12    24   4512\n   2     0    14    16   2192\n DNF     1     -     -      -\n"; out.String() != want {
+		t.Errorf("printTournament() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// boards winning on the same draw share a rank
+	_, tied := parseInput(t, "dim 2x2\n"+tiedInput)
+	for _, r := range tournamentRanking(tied, []int{1, 2}) {
+		if r.Rank != 1 {
+			t.Errorf("tied board %d ranked %d, want 1", r.Index, r.Rank)
+		}
+	}
+}
+
+func TestPivotalNumbers(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	// a copy of board 0 also wins with 16, and a board of undrawn numbers
+	// never wins
+	boards = append(boards, boards[0].clone(), parseBoard(t, grid(5, 5, 100)))
+	boards[3].index, boards[4].index = 3, 4
+	pivotal := pivotalNumbers(boards, numbers)
+	if want := map[int]int{24: 1, 16: 2, 13: 1}; !maps.Equal(pivotal, want) {
+		t.Errorf("pivotalNumbers() = %v, want %v", pivotal, want)
+	}
+
+	var out strings.Builder
+	printPivotal(&out, pivotal, append(numbers, 16))
+	equalLines(t, strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"), []string{
+		"draw #12 (24): 1 boards won",
+		"draw #14 (16): 2 boards won",
+		"draw #15 (13): 1 boards won",
+	})
+}
+
+func TestSimultaneousWinPairs(t *testing.T) {
+	input := "dim 2x2\n1,2,3,4\n\n1 2\n3 4\n\n3 4\n5 6\n\n2 9\n1 8\n\n7 8\n9 6\n"
+	_, boards := parseInput(t, input)
+	// boards 0 (row 0) and 2 (column 0) both win with 2, board 1 with 4
+	numbers := []int{1, 2, 3, 4}
+	if got, want := simultaneousWinPairs(boards, numbers), [][2]int{{0, 2}}; !slices.Equal(got, want) {
+		t.Errorf("simultaneousWinPairs() = %v, want %v", got, want)
+	}
+	if got := simultaneousWinPairs(boards, numbers[:1]); got != nil {
+		t.Errorf("simultaneousWinPairs() without winners = %v, want none", got)
+	}
+
+	lines := playLines(t, "-simultaneous", writeFile(t, "input", input))
+	if want := "boards 0 and 2 win on the same draw"; !slices.Contains(lines, want) {
+		t.Errorf("-simultaneous printed:\n%s\nwant %q", strings.Join(lines, "\n"), want)
+	}
+}
+
+func TestWinDOT(t *testing.T) {
+	input := "dim 2x2\n1,2,3,4\n\n1 2\n3 4\n\n3 4\n5 6\n\n2 9\n1 8\n\n7 8\n9 6\n"
+	dot := filepath.Join(t.TempDir(), "wins.dot")
+	playLines(t, "-dot", dot, writeFile(t, "input", input))
+	data, err := os.ReadFile(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if lines[0] != "graph wins {" || lines[len(lines)-1] != "}" {
+		t.Fatalf("not a graph:\n%s", data)
+	}
+	node := regexp.MustCompile(`^  b\d+ \[label=".*"(, style=dashed)?\];$`)
+	edge := regexp.MustCompile(`^  b\d+ -- b\d+ \[label="\d+"\];$`)
+	var nodes, edges []string
+	for _, line := range lines[1 : len(lines)-1] {
+		switch {
+		case node.MatchString(line):
+			nodes = append(nodes, line)
+		case edge.MatchString(line):
+			edges = append(edges, line)
+		default:
+			t.Errorf("unexpected DOT line %q", line)
+		}
+	}
+	// boards 0 and 2 share the kill shot 2, board 3 never wins
+	if len(nodes) != 4 || len(edges) != 1 || edges[0] != `  b0 -- b2 [label="2"];` {
+		t.Errorf("%d nodes and edges %q, want 4 nodes and one edge b0 -- b2", len(nodes), edges)
+	}
+	if want := `  b3 [label="board 3\nno win", style=dashed];`; !slices.Contains(nodes, want) {
+		t.Errorf("nodes %q, want %q", nodes, want)
+	}
+}
+
+func TestGameFingerprint(t *testing.T) {
+	// golden: update only for a deliberate change of the game's results
+	const golden = "6a3cc03fb15602e24254a039d42deb22c94e66a6ce080215f397a119517e0cfd"
+	numbers, boards := parseInput(t, sampleInput)
+	if got := gameFingerprint(boards, numbers); got != golden {
+		t.Errorf("gameFingerprint() = %s, want %s", got, golden)
+	}
+	if boards[2].markedCount() != 0 {
+		t.Error("gameFingerprint marked the boards it was given")
+	}
+	equalLines(t, playLines(t, "-fingerprint", writeFile(t, "input", sampleInput)), []string{golden})
+
+	for name, fingerprint := range map[string]string{
+		"fewer draws":     gameFingerprint(boards, numbers[:14]),
+		"without board 2": gameFingerprint(boards[:2], numbers),
+	} {
+		if fingerprint == golden {
+			t.Errorf("%s: fingerprint did not change", name)
+		}
+	}
+}
+
+func TestPrintProgress(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	var out strings.Builder
+	printProgress(&out, boards, numbers)
+	// the game ends with the part 1 win on draw #12
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if want := "draw #01 (7): possible lines min 10, max 10, total 30"; len(lines) != 12 || lines[0] != want {
+		t.Errorf("printProgress() printed %d lines starting %q, want 12 starting %q", len(lines), lines[0], want)
+	}
+
+	out.Reset()
+	printProgress(&out, nil, numbers[:1])
+	if want := "draw #01 (7): possible lines min 0, max 0, total 0\n"; out.String() != want {
+		t.Errorf("printProgress() without boards = %q, want %q", out.String(), want)
+	}
+}
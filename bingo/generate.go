@@ -0,0 +1,116 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// defaultMaxNumber bounds generated numbers to 0-99, like the AoC inputs
+const defaultMaxNumber = 100
+
+// generateInput writes a random puzzle input: a shuffled draws line of every
+// number below maxNumber followed by size x size boards of distinct numbers.
+// Boards of other than the default size are announced by a "dim" header.
+func generateInput(w io.Writer, rng *rand.Rand, boards, maxNumber, size int) {
+	if size != boardSize {
+		fmt.Fprintln(w, dimensionsHeaderPrefix+dimensions{size, size}.String())
+	}
+	numbers := rng.Perm(maxNumber)
+	draws := make([]string, len(numbers))
+	for i, number := range numbers {
+		draws[i] = fmt.Sprint(number)
+	}
+	fmt.Fprintln(w, strings.Join(draws, ","))
+	for b := 0; b < boards; b++ {
+		fmt.Fprintln(w)
+		values := rng.Perm(maxNumber)[:size*size]
+		for y := 0; y < size; y++ {
+			row := make([]string, size)
+			for x := range row {
+				row[x] = fmt.Sprintf("%2d", values[y*size+x])
+			}
+			fmt.Fprintln(w, strings.Join(row, " "))
+		}
+	}
+}
+
+// generateLosingBoard returns a board of distinct numbers below
+// defaultMaxNumber that are all absent from numbers, so it never wins when
+// they are drawn. It fails when too few such numbers remain.
+func generateLosingBoard(numbers []int, rng *rand.Rand) (board, error) {
+	drawn := map[int]bool{}
+	for _, number := range numbers {
+		drawn[number] = true
+	}
+	var free []int
+	for _, value := range rng.Perm(defaultMaxNumber) {
+		if !drawn[value] {
+			free = append(free, value)
+		}
+	}
+	if len(free) < boardSize*boardSize {
+		return board{}, errors.New("not enough undrawn numbers for a losing board")
+	}
+	b := newBoard(dimensions{boardSize, boardSize})
+	for y := range b.numbers {
+		copy(b.numbers[y], free[y*boardSize:(y+1)*boardSize])
+	}
+	return b, nil
+}
+
+// fixtureAnswers are the expected results of a generated input, null for a
+// part without a winner
+type fixtureAnswers struct {
+	Part1 *int `json:"part1"`
+	Part2 *int `json:"part2"`
+}
+
+// solveFixture computes the answers of a generated input from a full game,
+// so they do not depend on the part 1 and part 2 solvers being checked
+func solveFixture(input []byte) (answers fixtureAnswers) {
+	scanner, dims, err := textInput(bytes.NewReader(input), boardSize)
+	check(err)
+	numbers, err := parseNumberDraws(scanner, ",")
+	check(err)
+	boards, err := parseNumberBoards(scanner, "", dims)
+	check(err)
+	wins := playFullGame(boards, numbers)
+	first, last := -1, -1
+	for _, win := range wins {
+		if !win.won {
+			// part 2 needs every board to win eventually
+			last = -2
+			continue
+		}
+		// the highest score wins ties on both the first and the last draw
+		if first < 0 || win.draw < wins[first].draw ||
+			(win.draw == wins[first].draw && win.score > wins[first].score) {
+			first = win.board
+		}
+		if last != -2 && (last < 0 || win.draw > wins[last].draw ||
+			(win.draw == wins[last].draw && win.score > wins[last].score)) {
+			last = win.board
+		}
+	}
+	if first >= 0 {
+		answers.Part1 = &wins[first].score
+	}
+	if last >= 0 {
+		answers.Part2 = &wins[last].score
+	}
+	return
+}
+
+func writeAnswers(filename string, answers fixtureAnswers) error {
+	data, err := json.MarshalIndent(answers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,104 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateLosingBoard(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	numbers, boards := parseInput(t, sampleInput)
+	for trial := 0; trial < 20; trial++ {
+		losing, err := generateLosingBoard(numbers, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, number := range numbers {
+			if _, _, ok := losing.find(number); ok {
+				t.Fatalf("losing board holds the draw %d:\n%s", number, losing)
+			}
+		}
+		losing.index = len(boards)
+		if index, ok := boardWinIndex(losing, numbers); ok {
+			t.Fatalf("losing board won on draw %d:\n%s", index, losing)
+		}
+		// part 2 cannot finish with a board that never wins
+		if result := playBingoWorstChoice(append(cloneBoards(boards), losing), numbers); result.Won {
+			t.Fatalf("part 2 won with a losing board: %v", result)
+		}
+	}
+
+	// 80 of the 100 numbers drawn leave too few for 25 cells
+	var most []int
+	for number := 0; number < 80; number++ {
+		most = append(most, number)
+	}
+	if _, err := generateLosingBoard(most, rng); err == nil {
+		t.Error("generated a losing board from 20 undrawn numbers")
+	}
+}
+
+func TestGenerateWithAnswers(t *testing.T) {
+	for _, seed := range []string{"1", "2", "42"} {
+		t.Run(seed, func(t *testing.T) {
+			answersFile := filepath.Join(t.TempDir(), "answers.json")
+			input, stderr, code := runMain(t, "generate", "-seed", seed, "-boards", "30", "-generate-with-answers", answersFile)
+			if code != 0 {
+				t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+			}
+			data, err := os.ReadFile(answersFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var answers fixtureAnswers
+			if err := json.Unmarshal(data, &answers); err != nil {
+				t.Fatal(err)
+			}
+
+			numbers, boards := parseInput(t, input)
+			for part, tt := range []struct {
+				result GameResult
+				answer *int
+			}{
+				{playBingoBestChoice(cloneBoards(boards), numbers), answers.Part1},
+				{playBingoWorstChoice(cloneBoards(boards), numbers), answers.Part2},
+			} {
+				switch {
+				case tt.answer == nil && tt.result.Won:
+					t.Errorf("part%d: won with %v, the answers have none", part+1, tt.result)
+				case tt.answer != nil && (!tt.result.Won || tt.result.Score != *tt.answer):
+					t.Errorf("part%d: %v, the answer is %d", part+1, tt.result, *tt.answer)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSize(t *testing.T) {
+	for _, size := range []int{1, 3, boardSize, 7} {
+		var input bytes.Buffer
+		generateInput(&input, rand.New(rand.NewSource(1)), 4, 64, size)
+		_, boards := parseInput(t, input.String())
+		if len(boards) != 4 || boards[3].dimensions() != (dimensions{size, size}) {
+			t.Errorf("-size %d: generated %d boards of %v", size, len(boards), boards[len(boards)-1].dimensions())
+		}
+		if header := strings.HasPrefix(input.String(), dimensionsHeaderPrefix); header != (size != boardSize) {
+			t.Errorf("-size %d: dimensions header = %t", size, header)
+		}
+	}
+
+	lines := playLines(t, "generate", "-seed", "1", "-boards", "2", "-size", "3", "-max", "9")
+	if lines[0] != "dim 3x3" || len(lines) != 2+2*4 {
+		t.Errorf("generate -size 3 printed:\n%s", strings.Join(lines, "\n"))
+	}
+	for _, args := range [][]string{{"-size", "0"}, {"-size", "3", "-max", "8"}} {
+		if _, err := parseGenerateArgs(args); err == nil {
+			t.Errorf("generate %s parsed without error", strings.Join(args, " "))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package bingo
+
+import (
+	"sort"
+	"time"
+)
+
+// patternsThrough returns, for every cell of a board of the given size, the
+// indices into lines of the patterns passing through it
+func patternsThrough(dims dimensions, lines []line) [][][]int {
+	through := make([][][]int, dims.rows)
+	for y := range through {
+		through[y] = make([][]int, dims.cols)
+	}
+	for l, cells := range lines {
+		for _, cell := range cells {
+			through[cell[0]][cell[1]] = append(through[cell[0]][cell[1]], l)
+		}
+	}
+	return through
+}
+
+// playFirstWinIncremental plays part 1 like playBingoBestChoice, but instead
+// of rescanning every board after each draw it keeps a count of unmarked
+// cells per win pattern and board, and only touches the cells holding the
+// drawn number. A board wins the moment enough of its counts reach zero.
+// Numbers not on a board are ignored; a number appearing on several cells of
+// one board marks all of them, and a number drawn again marks nothing new.
+func playFirstWinIncremental(boards []board, numbers []int) (result GameResult) {
+	defer timeit(time.Now(), "playFirstWinIncremental")
+	boards = cloneBoards(boards)
+	positions := numberPositions(boards)
+	through := map[dimensions][][][]int{}
+	unmarked := make([][]int, len(boards))
+	completed := make([]int, len(boards))
+	// boards complete before any draw, through free cells, win on the first
+	var won []int
+	for i, b := range boards {
+		dims := b.dimensions()
+		lines := winLines(dims)
+		if _, ok := through[dims]; !ok {
+			through[dims] = patternsThrough(dims, lines)
+		}
+		unmarked[i] = make([]int, len(lines))
+		for l, cells := range lines {
+			for _, cell := range cells {
+				if !b.marked[cell[0]][cell[1]] {
+					unmarked[i][l]++
+				}
+			}
+			if unmarked[i][l] == 0 {
+				completed[i]++
+			}
+		}
+		if completed[i] >= winOptions.Lines {
+			won = append(won, i)
+		}
+	}
+
+	for draw, number := range numbers {
+		for _, ref := range positions[number] {
+			b := boards[ref.board]
+			if b.marked[ref.row][ref.col] {
+				continue
+			}
+			b.marked[ref.row][ref.col] = true
+			for _, l := range through[b.dimensions()][ref.row][ref.col] {
+				if unmarked[ref.board][l]--; unmarked[ref.board][l] == 0 {
+					if completed[ref.board]++; completed[ref.board] == winOptions.Lines {
+						won = append(won, ref.board)
+					}
+				}
+			}
+		}
+		if len(won) == 0 {
+			continue
+		}
+		// keep input order so that ties pick the same board as
+		// playBingoBestChoice
+		sort.Ints(won)
+		winners := make([]board, len(won))
+		for i, index := range won {
+			winners[i] = boards[index]
+		}
+		return winResult(findHighestScoringBoard(winners), winners, numbers, draw)
+	}
+	return
+}
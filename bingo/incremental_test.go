@@ -0,0 +1,55 @@
+package bingo
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"testing"
+)
+
+func TestIncrementalMatchesRescan(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	if got := playFirstWinIncremental(boards, numbers); got.Score != 4512 || got.Board.index != 2 || got.Draw != 11 {
+		t.Errorf("sample: playFirstWinIncremental() = %v on board %d, draw %d, want 4512 on board 2, draw 11", got, got.Board.index, got.Draw)
+	}
+	if boards[2].markedCount() != 0 {
+		t.Error("playFirstWinIncremental marked the boards it was given")
+	}
+
+	for _, opts := range []WinOptions{defaultWinOptions, {Rows: true, Columns: true, Diagonals: true, Lines: 1}, {Rows: true, Columns: true, Lines: 2}} {
+		t.Run(fmt.Sprintf("%+v", opts), func(t *testing.T) {
+			useWinOptions(t, opts)
+			for seed := int64(1); seed <= 20; seed++ {
+				var input bytes.Buffer
+				// few numbers make repeated cells and simultaneous wins likely
+				generateInput(&input, rand.New(rand.NewSource(seed)), 30, 30, 5)
+				numbers, boards := parseInput(t, input.String())
+				want := playBingoBestChoice(cloneBoards(boards), numbers)
+				got := playFirstWinIncremental(boards, numbers)
+				if got.Won != want.Won || got.Score != want.Score || got.Draw != want.Draw || got.Board.index != want.Board.index {
+					t.Errorf("seed %d: incremental %v on board %d, draw %d, want %v on board %d, draw %d",
+						seed, got, got.Board.index, got.Draw, want, want.Board.index, want.Draw)
+				}
+			}
+		})
+	}
+
+	equalLines(t, playLines(t, "-incremental", writeFile(t, "input", sampleInput)), []string{"part1 result: 4512", "part2 result: 1924"})
+}
+
+func BenchmarkPart1(b *testing.B) {
+	numbers, boards := largeInput(b, 1000)
+	captureLog(slog.LevelWarn, func() {
+		b.Run("rescan", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				playBingoBestChoice(cloneBoards(boards), numbers)
+			}
+		})
+		b.Run("incremental", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				playFirstWinIncremental(boards, numbers)
+			}
+		})
+	})
+}
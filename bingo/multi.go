@@ -0,0 +1,193 @@
+package bingo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// fileResult holds both parts' results for one input file
+type fileResult struct {
+	filename     string
+	boards       int
+	part1, part2 GameResult
+	err          error
+}
+
+// playFiles parses and plays every input file using up to concurrency
+// workers. Each file gets its own boards, and results keep the input order.
+func playFiles(opts inputOptions, concurrency int) []fileResult {
+	results := make([]fileResult, len(opts.filenames))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fileOpts := opts
+				fileOpts.filename = opts.filenames[i]
+				results[i] = playFile(fileOpts)
+			}
+		}()
+	}
+	for i := range opts.filenames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// playFile plays both parts on one input file. Errors are returned in the
+// result instead of aborting the other files.
+func playFile(opts inputOptions) (result fileResult) {
+	result.filename = opts.filename
+	defer recoverFatal(&result.err)
+	numbers, boards := loadInput(opts)
+	result.boards = len(boards)
+	result.part1 = playBingoBestChoice(cloneBoards(boards), numbers)
+	result.part2 = playBingoWorstChoice(cloneBoards(boards), numbers)
+	return
+}
+
+// printComparison writes a small report comparing the results of two files
+// and reports whether both parts match
+func printComparison(w io.Writer, a, b fileResult) bool {
+	fmt.Fprintf(w, "compare %s vs %s\n", a.filename, b.filename)
+	match := true
+	for part, pair := range [][2]GameResult{{a.part1, b.part1}, {a.part2, b.part2}} {
+		x, y := pair[0], pair[1]
+		switch {
+		case x.Won != y.Won:
+			match = false
+			fmt.Fprintf(w, "part%d: %v vs %v (differs)\n", part+1, x, y)
+		case x.Score != y.Score:
+			match = false
+			fmt.Fprintf(w, "part%d: %v vs %v (differs by %d)\n", part+1, x, y, y.Score-x.Score)
+		default:
+			fmt.Fprintf(w, "part%d: %v vs %v (match)\n", part+1, x, y)
+		}
+		if x.Won && y.Won && x.Draw != y.Draw {
+			fmt.Fprintf(w, "part%d: won on draw #%02d vs #%02d\n", part+1, x.Draw+1, y.Draw+1)
+		}
+	}
+	return match
+}
+
+// partStats aggregates one part's results over several files. Draw indices
+// and scores only cover files where a board won.
+type partStats struct {
+	Won       int         `json:"won"`
+	Draws     map[int]int `json:"draws"` // winning draw index -> number of files
+	MinScore  int         `json:"min_score"`
+	MaxScore  int         `json:"max_score"`
+	MeanScore float64     `json:"mean_score"`
+}
+
+func (s *partStats) add(result GameResult) {
+	if !result.Won {
+		return
+	}
+	if s.Won == 0 || result.Score < s.MinScore {
+		s.MinScore = result.Score
+	}
+	if s.Won == 0 || result.Score > s.MaxScore {
+		s.MaxScore = result.Score
+	}
+	// keep a running mean so no separate total is needed
+	s.Won++
+	s.MeanScore += (float64(result.Score) - s.MeanScore) / float64(s.Won)
+	s.Draws[result.Draw]++
+}
+
+// aggregateStats summarizes the results of playing several files
+type aggregateStats struct {
+	Files  int       `json:"files"`
+	Failed int       `json:"failed"`
+	Boards int       `json:"boards"`
+	Part1  partStats `json:"part1"`
+	Part2  partStats `json:"part2"`
+}
+
+func aggregateResults(results []fileResult) aggregateStats {
+	stats := aggregateStats{
+		Files: len(results),
+		Part1: partStats{Draws: map[int]int{}},
+		Part2: partStats{Draws: map[int]int{}},
+	}
+	for _, result := range results {
+		if result.err != nil {
+			stats.Failed++
+			continue
+		}
+		stats.Boards += result.boards
+		stats.Part1.add(result.part1)
+		stats.Part2.add(result.part2)
+	}
+	return stats
+}
+
+func writeStatsJSON(w io.Writer, stats aggregateStats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+// splitBoards partitions boards into consecutive groups of n, the last group
+// holding whatever is left over
+func splitBoards(boards []board, n int) (groups [][]board) {
+	for len(boards) > n {
+		groups = append(groups, boards[:n])
+		boards = boards[n:]
+	}
+	if len(boards) > 0 {
+		groups = append(groups, boards)
+	}
+	return
+}
+
+// printGroupResults plays every group of n boards as a separate game against
+// the shared draws and prints both results of each group
+func printGroupResults(w io.Writer, boards []board, numbers []int, n int) {
+	for i, group := range splitBoards(boards, n) {
+		label := fmt.Sprintf("group %d (boards %d-%d)", i, group[0].index, group[len(group)-1].index)
+		fmt.Fprintf(w, "%s: part1 result: %v\n", label, playBingoBestChoice(cloneBoards(group), numbers))
+		fmt.Fprintf(w, "%s: part2 result: %v\n", label, playBingoWorstChoice(cloneBoards(group), numbers))
+	}
+}
+
+// printResultTable writes one row per file with its results and winning
+// draws, padding every column to its widest cell. Files that failed to play
+// or parts without a winner show "-".
+func printResultTable(w io.Writer, results []fileResult) {
+	rows := [][]string{{"file", "part1", "part2", "draw1", "draw2"}}
+	for _, result := range results {
+		row := []string{result.filename, "-", "-", "-", "-"}
+		if result.err == nil {
+			for part, r := range []GameResult{result.part1, result.part2} {
+				if r.Won {
+					row[1+part] = strconv.Itoa(r.Score)
+					row[3+part] = strconv.Itoa(r.Draw + 1)
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, row := range rows {
+		// the file name is left-aligned, the numbers right-aligned
+		line := fmt.Sprintf("%-*s", widths[0], row[0])
+		for i := 1; i < len(row); i++ {
+			line += fmt.Sprintf("  %*s", widths[i], row[i])
+		}
+		fmt.Fprintln(w, line)
+	}
+}
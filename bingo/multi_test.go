@@ -0,0 +1,152 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlayFilesKeepsInputOrder(t *testing.T) {
+	t.Cleanup(saveGlobals())
+	args := []string{writeFile(t, "sample", sampleInput)}
+	for seed := int64(1); seed <= 8; seed++ {
+		var input bytes.Buffer
+		generateInput(&input, rand.New(rand.NewSource(seed)), 20, defaultMaxNumber, boardSize)
+		args = append(args, writeFile(t, fmt.Sprintf("generated%d", seed), input.String()))
+	}
+	args = append(args, filepath.Join(t.TempDir(), "missing"))
+	opts, err := parseValidateArgs(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial := playFiles(opts, 1)
+	if serial[0].part1.Score != 4512 || serial[0].part2.Score != 1924 {
+		t.Errorf("sample: %v, %v, want 4512, 1924", serial[0].part1, serial[0].part2)
+	}
+	if serial[len(serial)-1].err == nil {
+		t.Error("missing file played without error")
+	}
+	for _, concurrency := range []int{2, 4, 16} {
+		for i, got := range playFiles(opts, concurrency) {
+			want := serial[i]
+			if got.filename != want.filename || got.part1.Score != want.part1.Score || got.part2.Score != want.part2.Score || (got.err == nil) != (want.err == nil) {
+				t.Errorf("concurrency %d, file %d: %s %v %v %v, want %s %v %v %v", concurrency, i,
+					got.filename, got.part1, got.part2, got.err, want.filename, want.part1, want.part2, want.err)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	blocks := strings.Split(strings.TrimSuffix(sampleInput, "\n"), "\n\n")
+	shuffled := strings.Join([]string{blocks[0], blocks[3], blocks[1], blocks[2]}, "\n\n") + "\n"
+	input := writeFile(t, "input", sampleInput)
+	shuffledInput := writeFile(t, "shuffled", shuffled)
+	equalLines(t, playLines(t, "-compare", shuffledInput, input), []string{
+		"compare " + input + " vs " + shuffledInput,
+		"part1: 4512 vs 4512 (match)",
+		"part2: 1924 vs 1924 (match)",
+	})
+
+	// without the part 1 winner, part 1 is won later by another board
+	t.Cleanup(saveGlobals())
+	opts, err := parseValidateArgs([]string{input, writeFile(t, "fewer", strings.Join(blocks[:3], "\n\n")+"\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := playFiles(opts, 1)
+	var report strings.Builder
+	if printComparison(&report, results[0], results[1]) {
+		t.Errorf("inputs with different winners match:\n%s", report.String())
+	}
+	if want := "part1: 4512 vs 2192 (differs by -2320)\npart1: won on draw #12 vs #14\n"; !strings.Contains(report.String(), want) {
+		t.Errorf("report is missing %q:\n%s", want, report.String())
+	}
+	if stdout, _, code := runMain(t, "-compare", opts.filenames[1], input); code != 1 || !strings.Contains(stdout, "differs") {
+		t.Errorf("differing -compare: exit status %d, want 1, stdout:\n%s", code, stdout)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	blocks := strings.Split(strings.TrimSuffix(sampleInput, "\n"), "\n\n")
+	sample := writeFile(t, "sample", sampleInput)
+	// without its part 1 winner, board 0 wins part 1 on draw index 13
+	fewer := writeFile(t, "fewer", strings.Join(blocks[:3], "\n\n")+"\n")
+	stdout, stderr, code := runMain(t, "-stats-json", sample, fewer)
+	if code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr)
+	}
+	var got aggregateStats
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := aggregateStats{
+		Files:  2,
+		Boards: 5,
+		Part1:  partStats{Won: 2, Draws: map[int]int{11: 1, 13: 1}, MinScore: 2192, MaxScore: 4512, MeanScore: 3352},
+		Part2:  partStats{Won: 2, Draws: map[int]int{14: 2}, MinScore: 1924, MaxScore: 1924, MeanScore: 1924},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// files that fail to play are counted but not aggregated
+	results := []fileResult{{filename: "missing", err: errors.New("missing")}}
+	if stats := aggregateResults(results); stats.Files != 1 || stats.Failed != 1 || stats.Part1.Won != 0 {
+		t.Errorf("failed file: stats = %+v", stats)
+	}
+	missing := filepath.Join(t.TempDir(), "missing")
+	if stdout, _, code := runMain(t, "-stats-json", sample, missing); code != 1 || !strings.Contains(stdout, `"failed": 1`) {
+		t.Errorf("-stats-json with a missing file: exit status %d, want 1, stdout:\n%s", code, stdout)
+	}
+	if stdout, _, code := runMain(t, sample, missing); code != 1 || !strings.Contains(stdout, "part1 result: 4512") {
+		t.Errorf("several inputs with a missing one: exit status %d, want 1, stdout:\n%s", code, stdout)
+	}
+}
+
+func TestBoardsPerFile(t *testing.T) {
+	draws, boards, _ := strings.Cut(sampleInput, "\n\n")
+	blocks := strings.Split(strings.TrimSpace(boards), "\n\n")
+	// the second group repeats board 0 in place of board 2
+	six := append(blocks, blocks[0], blocks[1], blocks[0])
+	input := writeFile(t, "six", draws+"\n\n"+strings.Join(six, "\n\n")+"\n")
+	equalLines(t, playLines(t, "-boards-per-file", "3", input), []string{
+		"group 0 (boards 0-2): part1 result: 4512",
+		"group 0 (boards 0-2): part2 result: 1924",
+		"group 1 (boards 3-5): part1 result: 2192",
+		"group 1 (boards 3-5): part2 result: 1924",
+	})
+
+	var sizes []int
+	for _, group := range splitBoards(make([]board, 7), 3) {
+		sizes = append(sizes, len(group))
+	}
+	if want := []int{3, 3, 1}; !slices.Equal(sizes, want) {
+		t.Errorf("splitBoards(7 boards, 3) group sizes = %v, want %v", sizes, want)
+	}
+}
+
+func TestResultTable(t *testing.T) {
+	won := func(score, draw int) GameResult { return GameResult{Won: true, Score: score, Draw: draw} }
+	var out bytes.Buffer
+	printResultTable(&out, []fileResult{
+		{filename: "a", part1: won(4512, 11), part2: won(1924, 14)},
+		{filename: "inputs/longer-name", part1: won(7, 4), part2: GameResult{}},
+		{filename: "missing", err: errors.New("no such file")},
+	})
+	want := []string{
+		"file                part1  part2  draw1  draw2",
+		"a                    4512   1924     12     15",
+		"inputs/longer-name      7      -      5      -",
+		"missing                 -      -      -      -",
+	}
+	equalLines(t, strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"), want)
+}
@@ -0,0 +1,52 @@
+package bingo
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelThreshold is the number of boards from which marking draws and
+// checking for wins is split over a worker pool. Below it the goroutine
+// overhead outweighs the speedup.
+const parallelThreshold = 4096
+
+// forEachChunk splits [0, n) into one chunk per GOMAXPROCS worker, calls fn
+// on every chunk concurrently and waits for all of them
+func forEachChunk(n int, fn func(lo, hi int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers == 1 {
+		fn(0, n)
+		return
+	}
+	size := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += size {
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(lo, min(lo+size, n))
+	}
+	wg.Wait()
+}
+
+// wonBoards reports for every board whether it has won, checking large board
+// counts in parallel
+func wonBoards(boards []board) []bool {
+	return currentRules().wonBoards(boards)
+}
+
+func (r rules) wonBoards(boards []board) []bool {
+	won := make([]bool, len(boards))
+	check := func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			won[i] = r.boardWon(boards[i])
+		}
+	}
+	if len(boards) < parallelThreshold {
+		check(0, len(boards))
+	} else {
+		forEachChunk(len(boards), check)
+	}
+	return won
+}
@@ -0,0 +1,84 @@
+package bingo
+
+import (
+	"bytes"
+	"log/slog"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// largeInput parses a generated input of n boards, enough to take the
+// parallel path from parallelThreshold boards on
+func largeInput(tb testing.TB, n int) (numbers []int, boards []board) {
+	tb.Helper()
+	var input bytes.Buffer
+	generateInput(&input, rand.New(rand.NewSource(1)), n, defaultMaxNumber, boardSize)
+	numbers, boards, err := ParseInput(&input)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return numbers, boards
+}
+
+// withProcs runs fn with GOMAXPROCS set to procs, 1 making forEachChunk run
+// serially
+func withProcs(procs int, fn func()) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+	fn()
+}
+
+func TestParallelMatchesSerial(t *testing.T) {
+	numbers, boards := largeInput(t, 2*parallelThreshold)
+	var serial1, serial2 GameResult
+	withProcs(1, func() {
+		serial1 = playBingoBestChoice(cloneBoards(boards), numbers)
+		serial2 = playBingoWorstChoice(cloneBoards(boards), numbers)
+	})
+	withProcs(4, func() {
+		parallel1 := playBingoBestChoice(cloneBoards(boards), numbers)
+		parallel2 := playBingoWorstChoice(cloneBoards(boards), numbers)
+		if parallel1.Score != serial1.Score || parallel1.Board.index != serial1.Board.index {
+			t.Errorf("part 1: parallel %v on board %d, serial %v on board %d", parallel1, parallel1.Board.index, serial1, serial1.Board.index)
+		}
+		if parallel2.Score != serial2.Score || parallel2.Board.index != serial2.Board.index {
+			t.Errorf("part 2: parallel %v on board %d, serial %v on board %d", parallel2, parallel2.Board.index, serial2, serial2.Board.index)
+		}
+	})
+
+	// every chunk is visited exactly once
+	visits := make([]int, 1001)
+	withProcs(4, func() {
+		forEachChunk(len(visits), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				visits[i]++
+			}
+		})
+	})
+	for i, n := range visits {
+		if n != 1 {
+			t.Fatalf("index %d visited %d times", i, n)
+		}
+	}
+}
+
+func BenchmarkLargeInput(b *testing.B) {
+	numbers, boards := largeInput(b, 4*parallelThreshold)
+	for _, bm := range []struct {
+		name  string
+		procs int
+	}{
+		{"serial", 1},
+		{"parallel", runtime.GOMAXPROCS(0)},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			captureLog(slog.LevelWarn, func() {
+				withProcs(bm.procs, func() {
+					for i := 0; i < b.N; i++ {
+						playBingoWorstChoice(cloneBoards(boards), numbers)
+					}
+				})
+			})
+		})
+	}
+}
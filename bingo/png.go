@@ -0,0 +1,103 @@
+package bingo
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+const (
+	pngCellSize  = 40 // pixels per cell, excluding grid lines
+	pngGridWidth = 2  // pixels per grid line
+	pngFontScale = 3  // pixels per glyph dot
+)
+
+var (
+	pngBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	pngGrid       = color.RGBA{0x40, 0x40, 0x40, 0xff}
+	pngMarked     = color.RGBA{0xff, 0xd7, 0x00, 0xff}
+	pngInk        = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// pngGlyphs is a 3x5 dot bitmap font covering the characters of a number
+var pngGlyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawText draws text with the bitmap font centered in rect
+func drawText(img *image.RGBA, rect image.Rectangle, text string) {
+	const glyphWidth, glyphHeight, spacing = 3, 5, 1
+	width := (len(text)*(glyphWidth+spacing) - spacing) * pngFontScale
+	height := glyphHeight * pngFontScale
+	origin := image.Pt(
+		rect.Min.X+(rect.Dx()-width)/2,
+		rect.Min.Y+(rect.Dy()-height)/2)
+	for i, char := range text {
+		glyph := pngGlyphs[char]
+		left := origin.X + i*(glyphWidth+spacing)*pngFontScale
+		for gy, dots := range glyph {
+			for gx, dot := range dots {
+				if dot != '#' {
+					continue
+				}
+				corner := image.Pt(left+gx*pngFontScale, origin.Y+gy*pngFontScale)
+				fillRect(img, image.Rectangle{corner, corner.Add(image.Pt(pngFontScale, pngFontScale))}, pngInk)
+			}
+		}
+	}
+}
+
+// boardImage renders a board as a grid of cells with the original numbers,
+// highlighting marked cells
+func boardImage(b board) *image.RGBA {
+	rows, cols := len(b.numbers), len(b.numbers[0])
+	step := pngCellSize + pngGridWidth
+	img := image.NewRGBA(image.Rect(0, 0, cols*step+pngGridWidth, rows*step+pngGridWidth))
+	fillRect(img, img.Bounds(), pngGrid)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			corner := image.Pt(x*step+pngGridWidth, y*step+pngGridWidth)
+			cell := image.Rectangle{corner, corner.Add(image.Pt(pngCellSize, pngCellSize))}
+			val, marked := b.At(y, x)
+			if marked {
+				fillRect(img, cell, pngMarked)
+			} else {
+				fillRect(img, cell, pngBackground)
+			}
+			drawText(img, cell, fmt.Sprint(val))
+		}
+	}
+	return img
+}
+
+func writeBoardPNG(filename string, b board) error {
+	fd, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	if err := png.Encode(fd, boardImage(b)); err != nil {
+		return err
+	}
+	return fd.Close()
+}
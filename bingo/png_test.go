@@ -0,0 +1,39 @@
+package bingo
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	output := filepath.Join(t.TempDir(), "board.png")
+	playLines(t, "-png", output, input)
+
+	fd, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	img, err := png.Decode(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := pngCellSize + pngGridWidth
+	if size, want := img.Bounds().Size(), 5*step+pngGridWidth; size.X != want || size.Y != want {
+		t.Errorf("image size = %v, want %dx%d", size, want, want)
+	}
+	// the part 1 winner has its first row marked, 10 below it is not
+	cellColor := func(row, col int) color.Color {
+		return color.RGBAModel.Convert(img.At(col*step+pngGridWidth+1, row*step+pngGridWidth+1))
+	}
+	if got := cellColor(0, 0); got != pngMarked {
+		t.Errorf("marked cell color = %v, want %v", got, pngMarked)
+	}
+	if got := cellColor(1, 0); got != pngBackground {
+		t.Errorf("unmarked cell color = %v, want %v", got, pngBackground)
+	}
+}
@@ -0,0 +1,103 @@
+package bingo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadBoards parses only the boards of a text input file; a draws line, if
+// present, is skipped
+func loadBoards(opts inputOptions, filename string) []board {
+	fd, err := openInput(filename)
+	check(err)
+	defer fd.Close()
+	var boards []board
+	scanner, dims, err := textInput(fd, opts.size)
+	if err == nil {
+		boards, err = parseNumberBoards(scanner, opts.delim, dims)
+	}
+	if err != nil {
+		check(fmt.Errorf("%s: %w", filename, err))
+	}
+	if len(boards) == 0 {
+		check(fmt.Errorf("no boards found in %s", filename))
+	}
+	return boards
+}
+
+// loadBoardsDir reads a single board from every regular file in dir, in name
+// order. Boards without a "Board:" label are named after their file.
+func loadBoardsDir(opts inputOptions, dir string) (boards []board) {
+	filenames, err := boardFiles(dir)
+	check(err)
+	for _, filename := range filenames {
+		b, err := loadBoardFile(opts, filename)
+		check(err)
+		b.index = len(boards)
+		if b.name == "" {
+			b.name = filepath.Base(filename)
+		}
+		boards = append(boards, b)
+		check(opts.checkMaxBoards(dir, len(boards)))
+	}
+	if len(boards) == 0 {
+		check(fmt.Errorf("no boards found in %s", dir))
+	}
+	return
+}
+
+// boardFiles lists the regular files in dir, in name order
+func boardFiles(dir string) (filenames []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			filenames = append(filenames, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return filenames, nil
+}
+
+// loadBoardFile parses a file holding exactly one board. Errors already name
+// the file.
+func loadBoardFile(opts inputOptions, filename string) (b board, err error) {
+	defer recoverFatal(&err)
+	boards := loadBoards(opts, filename)
+	if len(boards) != 1 {
+		return b, fmt.Errorf("%s: expected one board, got %d", filename, len(boards))
+	}
+	return boards[0], nil
+}
+
+// playDrawLines plays fresh copies of the boards against every line of draws
+// read from r, writing one line with both parts' results per draw line until
+// EOF. Blank lines are skipped and malformed lines reported without stopping.
+// A non-nil keep filters the boards against each line's draws.
+func playDrawLines(r io.Reader, w io.Writer, boards []board, delim string, keep boardPredicate) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		numbers, err := parseIntList(line, delim)
+		if err != nil {
+			fmt.Fprintf(w, "invalid draws %q: %v\n", line, err)
+			continue
+		}
+		played := boards
+		if keep != nil {
+			played = filterBoards(boards, numbers, keep)
+		}
+		part1 := playBingoBestChoice(cloneBoards(played), numbers)
+		part2 := playBingoWorstChoice(cloneBoards(played), numbers)
+		fmt.Fprintf(w, "part1 result: %v, part2 result: %v\n", part1, part2)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,92 @@
+package bingo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// withStdin makes os.Stdin read content for the rest of the test
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	fd, err := os.Open(writeFile(t, "stdin", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdin
+	os.Stdin = fd
+	// drop standard input buffered by an earlier test
+	stdin.once, stdin.data, stdin.err = sync.Once{}, nil, nil
+	t.Cleanup(func() {
+		os.Stdin = saved
+		fd.Close()
+		stdin.once, stdin.data, stdin.err = sync.Once{}, nil, nil
+	})
+}
+
+func TestPlayDrawLines(t *testing.T) {
+	_, boards := parseInput(t, sampleInput)
+	draws := sampleDraws()
+	var out strings.Builder
+	if err := playDrawLines(strings.NewReader(draws+"\n1,2\nx\n"), &out, boards, ",", nil); err != nil {
+		t.Fatal(err)
+	}
+	equalLines(t, strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"), []string{
+		"part1 result: 4512, part2 result: 1924",
+		"part1 result: no winning board, part2 result: no winning board",
+		`invalid draws "x": strconv.Atoi: parsing "x": invalid syntax`,
+	})
+	if _, marked := boards[0].At(0, 4); marked {
+		t.Error("playDrawLines marked the boards it was given")
+	}
+
+	boardsFile := writeFile(t, "boards", sampleBoards())
+	withStdin(t, draws+draws)
+	equalLines(t, playLines(t, "-boards", boardsFile, "-only", "0,1"), []string{
+		"part1 result: 2192, part2 result: 1924",
+		"part1 result: 2192, part2 result: 1924",
+	})
+	if _, stderr, code := runMain(t, "-boards", boardsFile, "-max-boards", "2"); code != 1 || !strings.Contains(stderr, "-max-boards") {
+		t.Errorf("-max-boards 2: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestBoardsDir(t *testing.T) {
+	dir := t.TempDir()
+	blocks := strings.Split(strings.TrimSpace(sampleBoards()), "\n\n")
+	// written out of name order to check boards are read in name order
+	for _, i := range []int{2, 0, 1} {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("board%d.txt", i)), []byte(blocks[i]+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	boards := loadBoardsDir(inputOptions{delim: " ", size: boardSize}, dir)
+	if len(boards) != 3 {
+		t.Fatalf("loaded %d boards, want 3", len(boards))
+	}
+	for i, b := range boards {
+		if want := fmt.Sprintf("board%d.txt", i); b.index != i || b.name != want {
+			t.Errorf("board %d is %d %q, want %d %q", i, b.index, b.name, i, want)
+		}
+	}
+
+	draws := writeFile(t, "draws", sampleDraws())
+	equalLines(t, playLines(t, "-boards-dir", dir, "-draws", draws), []string{"part1 result: 4512 (board2.txt)", "part2 result: 1924 (board1.txt)"})
+	equalLines(t, playLines(t, "-boards-dir", dir, "-draws", draws, "-only", "2", "-summary"), []string{"part1=4512(n=24,i=11) part2=4512(n=24,i=11)"})
+
+	two := filepath.Join(dir, "two.txt")
+	if err := os.WriteFile(two, []byte(blocks[0]+"\n\n"+blocks[1]+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, code := runMain(t, "-boards-dir", dir, "-draws", draws)
+	if want := two + ": expected one board, got 2"; code != 1 || !strings.Contains(stderr, want) {
+		t.Errorf("file with two boards: exit status %d, stderr:\n%s", code, stderr)
+	}
+}
@@ -0,0 +1,34 @@
+package bingo
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// interruptContext returns a copy of parent that is canceled on the first
+// os.Interrupt. A second interrupt exits the process immediately. Until it is
+// installed, and after it is stopped, an interrupt ends the process as usual.
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt)
+	go handleInterrupts(ctx, sigs, cancel, os.Exit)
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
+}
+
+// handleInterrupts cancels the game on the first signal and calls exit on the
+// second one. It returns once ctx is done without a pending signal.
+func handleInterrupts(ctx context.Context, sigs <-chan os.Signal, cancel context.CancelFunc, exit func(int)) {
+	select {
+	case <-sigs:
+		cancel()
+	case <-ctx.Done():
+		return
+	}
+	<-sigs
+	exit(130)
+}
@@ -0,0 +1,119 @@
+package bingo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleInterrupts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 2)
+	exited := make(chan int, 1)
+	done := make(chan struct{})
+	go func() {
+		handleInterrupts(ctx, sigs, cancel, func(code int) { exited <- code })
+		close(done)
+	}()
+
+	sigs <- os.Interrupt
+	<-ctx.Done()
+	select {
+	case code := <-exited:
+		t.Fatalf("first interrupt exited with status %d", code)
+	case <-time.After(10 * time.Millisecond):
+	}
+	sigs <- os.Interrupt
+	if code := <-exited; code != 130 {
+		t.Errorf("second interrupt exited with status %d, want 130", code)
+	}
+	<-done
+
+	// a game ending normally stops the handler without exiting
+	ctx, cancel = context.WithCancel(context.Background())
+	done = make(chan struct{})
+	go func() {
+		handleInterrupts(ctx, make(chan os.Signal), cancel, func(code int) { exited <- code })
+		close(done)
+	}()
+	cancel()
+	<-done
+	if len(exited) > 0 {
+		t.Errorf("handler exited with status %d without a signal", <-exited)
+	}
+}
+
+func TestInterruptedGame(t *testing.T) {
+	t.Cleanup(saveGlobals())
+	numbers, boards := parseInput(t, sampleInput)
+	// pause before the 4th draw long enough for the cancel to arrive
+	drawDelays = []time.Duration{0, 0, 0, time.Hour}
+	for part, play := range []func(context.Context, []board, []int) (GameResult, error){
+		playBingoBestChoiceContext, playBingoWorstChoiceContext,
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		result, err := play(ctx, cloneBoards(boards), numbers)
+		var interrupted *interruptedError
+		if !errors.As(err, &interrupted) {
+			t.Fatalf("part%d: error %v, want *interruptedError", part+1, err)
+		}
+		if interrupted.draws != 3 || interrupted.remaining != 3 || !errors.Is(err, context.Canceled) {
+			t.Errorf("part%d: %v", part+1, err)
+		}
+		if result.Won {
+			t.Errorf("part%d: interrupted game won: %v", part+1, result)
+		}
+	}
+}
+
+func TestInterruptContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, stop := interruptContext(parent)
+	defer stop()
+	cancel()
+	<-ctx.Done()
+
+	// while installed, an interrupt cancels the context instead of the process
+	ctx, stop = interruptContext(context.Background())
+	defer stop()
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skip("cannot interrupt the test process:", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("interrupt did not cancel the context")
+	}
+}
+
+func TestInterruptedPlayStatus(t *testing.T) {
+	t.Cleanup(saveGlobals())
+	opts, err := parsePlayArgs([]string{writeFile(t, "input", sampleInput)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	saved := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = saved }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var status exitStatus
+	if err := playInput(ctx, opts); !errors.As(err, &status) || status != 130 {
+		t.Errorf("interrupted playInput() = %v, want exit status 130", err)
+	}
+}
@@ -0,0 +1,162 @@
+package bingo
+
+import (
+	"fmt"
+	"time"
+)
+
+// drawIndices maps every drawn number to the index of its first draw
+func drawIndices(numbers []int) map[int]int {
+	indices := make(map[int]int, len(numbers))
+	for draw, number := range numbers {
+		if _, ok := indices[number]; !ok {
+			indices[number] = draw
+		}
+	}
+	return indices
+}
+
+// lineDraw returns the index of the draw completing the line, the latest draw
+// of its cells, and false if some cell is never drawn. A line of only
+// wildcards completes on the first draw.
+func lineDraw(b board, l line, indices map[int]int) (completed int, ok bool) {
+	for _, cell := range l {
+		if b.isFree(cell[0], cell[1]) {
+			continue
+		}
+		draw, ok := indices[b.numbers[cell[0]][cell[1]]]
+		if !ok {
+			return 0, false
+		}
+		completed = max(completed, draw)
+	}
+	return completed, true
+}
+
+// winDraw returns the index of the draw on which the board wins, or -1. A
+// pattern completes with the latest draw of its cells, and the board wins once
+// winOptions.Lines patterns are complete.
+func winDraw(b board, indices map[int]int) int {
+	var completions []int
+	for _, l := range winLines(b.dimensions()) {
+		if completed, ok := lineDraw(b, l, indices); ok {
+			completions = append(completions, completed)
+		}
+	}
+	if len(completions) < winOptions.Lines {
+		return -1
+	}
+	// the Lines-th earliest completion, found by partial selection sort
+	for i := 0; i < winOptions.Lines; i++ {
+		for j := i + 1; j < len(completions); j++ {
+			if completions[j] < completions[i] {
+				completions[i], completions[j] = completions[j], completions[i]
+			}
+		}
+	}
+	return completions[winOptions.Lines-1]
+}
+
+// streamFirstWinner plays part 1 while reading the boards one at a time, so
+// only the current board and the best one so far are in memory. Instead of
+// marking every board on every draw, each board's winning draw is computed
+// from the draw index of its numbers. Every board is passed to prepare, if not
+// nil, before it is played, and skipped unless prepare keeps it.
+func streamFirstWinner(bs *boardScanner, numbers []int, prepare func(*board) (keep bool, err error)) (result GameResult) {
+	defer timeit(time.Now(), "streamFirstWinner")
+	indices := drawIndices(numbers)
+	for {
+		b, ok := bs.next()
+		if !ok {
+			check(bs.Err())
+			return
+		}
+		if prepare != nil {
+			keep, err := prepare(&b)
+			check(err)
+			if !keep {
+				continue
+			}
+		}
+		draw := winDraw(b, indices)
+		if draw < 0 || (result.Won && draw > result.Draw) {
+			continue
+		}
+		for y, row := range b.numbers {
+			for x, val := range row {
+				if i, ok := indices[val]; ok && i <= draw {
+					b.marked[y][x] = true
+				}
+			}
+		}
+		sum := calcBoardScore(b)
+		// on the same draw keep the first of the highest scoring boards, like
+		// findHighestScoringBoard
+		if result.Won && draw == result.Draw && sum <= result.Sum {
+			continue
+		}
+		factor := multiplier(numbers[:draw+1])
+		result = GameResult{
+			Won:         true,
+			Score:       sum * factor,
+			Multiplier:  factor,
+			Sum:         sum,
+			Draw:        draw,
+			Number:      numbers[draw],
+			Board:       b,
+			MarkedCells: markedCells(b),
+		}
+	}
+}
+
+// streamInput plays part 1 of the input without loading all boards. The board
+// options apply to each board as it is read, in the order loadInput applies
+// them to a loaded input.
+func streamInput(opts inputOptions) GameResult {
+	fd, err := openInput(opts.filename)
+	check(err)
+	defer fd.Close()
+
+	scanner, dims, numbers := textDrawsInput(fd, opts)
+	numbers = overrideDraws(opts, numbers)
+	drawn := numbers
+	numbers = dedupeDraws(numbers, opts.dedupe)
+	if opts.drawCount > 0 && opts.drawCount < len(numbers) {
+		numbers = numbers[:opts.drawCount]
+	}
+
+	var indices []int
+	only := map[int]bool{}
+	if opts.only != "" {
+		indices, err = parseIntList(opts.only, ",")
+		check(err)
+		for _, i := range indices {
+			only[i] = true
+		}
+	}
+	keep := opts.boardFilter()
+	count := 0
+	result := streamFirstWinner(newBoardScanner(scanner, opts.delim, dims), numbers, func(b *board) (bool, error) {
+		count++
+		if err := opts.checkMaxBoards(opts.filename, count); err != nil {
+			return false, err
+		}
+		if err := opts.prepareBoard(b); err != nil {
+			return false, err
+		}
+		if opts.only != "" && !only[b.index] {
+			return false, nil
+		}
+		if opts.strict {
+			warnSuspicious([]board{*b}, drawn)
+		}
+		return keep == nil || keep(*b, numbers), nil
+	})
+	// like selectBoards, but the number of boards is only known at the end
+	for _, i := range indices {
+		if i < 0 || i >= count {
+			check(fmt.Errorf("board index %d out of range [0, %d)", i, count))
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package bingo
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestStreamLargeInput(t *testing.T) {
+	var input bytes.Buffer
+	generateInput(&input, rand.New(rand.NewSource(1)), 5000, defaultMaxNumber, boardSize)
+	numbers, boards := parseInput(t, input.String())
+	want := playBingoBestChoice(cloneBoards(boards), numbers)
+	if !want.Won {
+		t.Fatal("generated input has no winner")
+	}
+
+	file := writeFile(t, "input", input.String())
+	equalLines(t, playLines(t, "-stream", file), []string{"part1 result: " + want.String()})
+
+	t.Cleanup(saveGlobals())
+	opts, err := parseValidateArgs([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := streamInput(opts)
+	if got.Score != want.Score || got.Draw != want.Draw || got.Board.index != want.Board.index {
+		t.Errorf("streamInput() = %v on draw %d by board %d, want %v on draw %d by board %d",
+			got, got.Draw, got.Board.index, want, want.Draw, want.Board.index)
+	}
+}
+
+func TestStreamBoardOptions(t *testing.T) {
+	file := writeFile(t, "input", sampleInput)
+	for _, args := range [][]string{
+		{"-only", "0,1"},
+		{"-filter", "contains:25"},
+		{"-filter", "contains:25", "-only", "0,2"},
+		{"-draw-count", "11"},
+		{"-max-boards", "3"},
+	} {
+		want := playLines(t, append(args, file)...)[0]
+		equalLines(t, playLines(t, append(args, "-stream", file)...), []string{want})
+	}
+
+	// -strict warns as each board is read
+	input := writeFile(t, "free", "dim 2x2\n1,2,3,4\n\n0 0\n5 6\n\n1 2\n3 4\n")
+	_, stderr, _ := runMain(t, "-stream", "-strict", "-zero-is-free", "-log-level", "warn", input)
+	for _, want := range []string{"board has a line complete before any draw", "numbers never drawn: board 0: 5,6"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("-stream -strict stderr = %q, want %q", stderr, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-max-boards", "2"}, "more than 2 boards (-max-boards)"},
+		{[]string{"-only", "1,3"}, "board index 3 out of range [0, 3)"},
+		{[]string{"-input-format", "json"}, "-stream only reads text input files"},
+		{[]string{"-boards-dir", t.TempDir(), "-draws-from-args", "1,2"}, "-stream only reads text input files"},
+	} {
+		_, stderr, code := runMain(t, append(tt.args, "-stream", file)...)
+		if code == 0 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("-stream %v: exit %d, stderr %q, want an error containing %q", tt.args, code, stderr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package bingo
+
+import "log/slog"
+
+// syslogTag identifies messages sent with -syslog
+const syslogTag = "aoc4"
+
+// summaryLogger is the part of a system logger used by -syslog, satisfied by
+// *syslog.Writer
+type summaryLogger interface {
+	Info(msg string) error
+	Close() error
+}
+
+// sendSummary sends the one-line summary of both results to logger
+func sendSummary(logger summaryLogger, part1, part2 GameResult) error {
+	return logger.Info(summary(part1, part2))
+}
+
+// syslogSummary sends the summary to the system logger. When no system logger
+// is available the summary is logged as a warning instead.
+func syslogSummary(part1, part2 GameResult) {
+	logger, err := newSyslog()
+	if err == nil {
+		defer logger.Close()
+		err = sendSummary(logger, part1, part2)
+	}
+	if err != nil {
+		slog.Warn("cannot send summary to syslog", "err", err, "summary", summary(part1, part2))
+	}
+}
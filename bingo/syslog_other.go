@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package bingo
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newSyslog() (summaryLogger, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,42 @@
+package bingo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakeSyslog records the messages sent to it
+type fakeSyslog struct {
+	messages []string
+	err      error
+}
+
+func (l *fakeSyslog) Info(msg string) error {
+	l.messages = append(l.messages, msg)
+	return l.err
+}
+
+func (l *fakeSyslog) Close() error { return nil }
+
+func TestSendSummary(t *testing.T) {
+	numbers, boards := parseInput(t, sampleInput)
+	part1 := playBingoBestChoice(cloneBoards(boards), numbers)
+	part2 := playBingoWorstChoice(cloneBoards(boards), numbers)
+
+	logger := &fakeSyslog{}
+	if err := sendSummary(logger, part1, part2); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"part1=4512(n=24,i=11) part2=1924(n=13,i=14)"}; !slices.Equal(logger.messages, want) {
+		t.Errorf("sent %q, want %q", logger.messages, want)
+	}
+
+	logger = &fakeSyslog{err: errors.New("connection refused")}
+	if err := sendSummary(logger, part1, GameResult{}); !errors.Is(err, logger.err) {
+		t.Errorf("sendSummary() error = %v, want %v", err, logger.err)
+	}
+	if want := []string{"part1=4512(n=24,i=11) part2=none"}; !slices.Equal(logger.messages, want) {
+		t.Errorf("sent %q, want %q", logger.messages, want)
+	}
+}
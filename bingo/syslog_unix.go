@@ -0,0 +1,9 @@
+//go:build !windows && !plan9
+
+package bingo
+
+import "log/syslog"
+
+func newSyslog() (summaryLogger, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, syslogTag)
+}
@@ -0,0 +1,124 @@
+package bingo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// drawDelays holds the pause before each draw when timed draws are played in
+// real time. It is nil when draws are played as fast as possible.
+var drawDelays []time.Duration
+
+// timedDraw is a drawn number with the moment it was drawn
+type timedDraw struct {
+	at     time.Time
+	number int
+}
+
+// parseTimestamp parses an RFC 3339 timestamp or a number of seconds since
+// the Unix epoch, possibly fractional
+func parseTimestamp(field string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(field, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339Nano, field)
+}
+
+// parseTimedDraws reads "timestamp,number" lines, skipping blank lines.
+// Timestamps must not go backwards.
+func parseTimedDraws(r io.Reader) (draws []timedDraw, err error) {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		timestamp, value, ok := strings.Cut(line, ",")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected timestamp,number, got %q", lineNumber, line)
+		}
+		at, err := parseTimestamp(strings.TrimSpace(timestamp))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp: %w", lineNumber, err)
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid number: %w", lineNumber, err)
+		}
+		if len(draws) > 0 && at.Before(draws[len(draws)-1].at) {
+			return nil, fmt.Errorf("line %d: timestamp %v is before the previous draw", lineNumber, at)
+		}
+		draws = append(draws, timedDraw{at, number})
+	}
+	return draws, scanner.Err()
+}
+
+// readTimedDraws reads the timed draws in filename and returns their numbers
+// and the delay before each draw, the first one having none
+func readTimedDraws(filename string) (numbers []int, delays []time.Duration) {
+	fd, err := openInput(filename)
+	check(err)
+	defer fd.Close()
+	draws, err := parseTimedDraws(fd)
+	if err != nil {
+		check(fmt.Errorf("%s: %w", filename, err))
+	}
+	for i, draw := range draws {
+		numbers = append(numbers, draw.number)
+		if i == 0 {
+			delays = append(delays, 0)
+		} else {
+			delays = append(delays, draw.at.Sub(draws[i-1].at))
+		}
+	}
+	return
+}
+
+// keptDelays returns the delays before the kept draws, given their indices in
+// increasing order, when the others are dropped: a dropped draw's delay adds
+// to the next kept draw so that the pace still follows the timestamps
+func keptDelays(delays []time.Duration, kept []int) []time.Duration {
+	merged := make([]time.Duration, len(kept))
+	previous := 0
+	for i, draw := range kept {
+		for j := previous; j <= draw && j < len(delays); j++ {
+			merged[i] += delays[j]
+		}
+		previous = draw + 1
+	}
+	return merged
+}
+
+// delaysAfter returns the delays without those of the first n draws, which
+// have already been waited for
+func delaysAfter(delays []time.Duration, n int) []time.Duration {
+	if delays == nil || n >= len(delays) {
+		return nil
+	}
+	rest := make([]time.Duration, len(delays))
+	copy(rest[n:], delays[n:])
+	return rest
+}
+
+// waitForDraw sleeps for the real-time delay before the given draw, if any,
+// and returns early with ctx's error once it is canceled
+func waitForDraw(ctx context.Context, draw int) error {
+	if draw >= len(drawDelays) || drawDelays[draw] <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(drawDelays[draw])
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
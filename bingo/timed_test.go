@@ -0,0 +1,94 @@
+package bingo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimedDraws(t *testing.T) {
+	input := "1000,7\n\n1000.5, 4\n2001-09-09T01:46:41Z,9\n"
+	draws, err := parseTimedDraws(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []timedDraw{
+		{time.Unix(1000, 0), 7},
+		{time.Unix(1000, 5e8), 4},
+		{time.Unix(1000000001, 0), 9},
+	}
+	if len(draws) != len(want) {
+		t.Fatalf("parsed %d draws, want %d", len(draws), len(want))
+	}
+	for i := range want {
+		if !draws[i].at.Equal(want[i].at) || draws[i].number != want[i].number {
+			t.Errorf("draw %d = %v, %d, want %v, %d", i, draws[i].at, draws[i].number, want[i].at, want[i].number)
+		}
+	}
+
+	for _, tt := range []struct{ input, err string }{
+		{"1000 7\n", "line 1: expected timestamp,number"},
+		{"1000,7\nyesterday,4\n", "line 2: invalid timestamp"},
+		{"1000,seven\n", "line 1: invalid number"},
+		{"1000,7\n\n999,4\n", "line 3: timestamp"},
+	} {
+		if _, err := parseTimedDraws(strings.NewReader(tt.input)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseTimedDraws(%q) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+// timedSampleDraws returns the sample draws as timed draw lines, 1ms apart
+func timedSampleDraws(t *testing.T) string {
+	numbers, _ := parseInput(t, sampleInput)
+	var sb strings.Builder
+	for i, number := range numbers {
+		fmt.Fprintf(&sb, "%.3f,%d\n", 1000+float64(i)/1000, number)
+	}
+	return sb.String()
+}
+
+func TestTimedDrawsPlay(t *testing.T) {
+	boards := writeFile(t, "boards", sampleBoards())
+	timed := writeFile(t, "timed.csv", timedSampleDraws(t))
+	plain := playLines(t, "-draws", writeFile(t, "draws", sampleDraws()), boards)
+	equalLines(t, playLines(t, "-timed-draws", timed, boards), plain)
+	equalLines(t, playLines(t, "-timed-draws", timed, "-realtime", boards), plain)
+
+	numbers, delays := readTimedDraws(timed)
+	if want, _ := parseInput(t, sampleInput); !slices.Equal(numbers, want) {
+		t.Errorf("numbers = %v, want %v", numbers, want)
+	}
+	if delays[0] != 0 || len(delays) != len(numbers) {
+		t.Errorf("%d delays starting with %v, want %d starting with 0", len(delays), delays[0], len(numbers))
+	}
+	for i, delay := range delays[1:] {
+		if delay < 999*time.Microsecond || delay > 1001*time.Microsecond {
+			t.Errorf("delay before draw %d = %v, want 1ms", i+1, delay)
+		}
+	}
+}
+
+func TestKeptDelays(t *testing.T) {
+	ms := func(ds ...int) (delays []time.Duration) {
+		for _, d := range ds {
+			delays = append(delays, time.Duration(d)*time.Millisecond)
+		}
+		return
+	}
+	// dropping draws 1 and 2 moves their delays to draw 3
+	if got, want := keptDelays(ms(0, 10, 20, 30, 40), []int{0, 3, 4}), ms(0, 60, 40); !slices.Equal(got, want) {
+		t.Errorf("keptDelays() = %v, want %v", got, want)
+	}
+	if got, want := keptDelays(ms(0, 10, 20), []int{0, 1, 2}), ms(0, 10, 20); !slices.Equal(got, want) {
+		t.Errorf("keeping every draw: keptDelays() = %v, want %v", got, want)
+	}
+	if got, want := delaysAfter(ms(0, 10, 20, 30), 2), ms(0, 0, 20, 30); !slices.Equal(got, want) {
+		t.Errorf("delaysAfter() = %v, want %v", got, want)
+	}
+	if got := delaysAfter(ms(0, 10), 2); got != nil {
+		t.Errorf("delaysAfter() every draw = %v, want nil", got)
+	}
+}
@@ -0,0 +1,65 @@
+package bingo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// fileState is what fileChanged compares to detect a changed file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileChanged reports whether the file's modification time or size differs
+// from last, along with its current state
+func fileChanged(filename string, last fileState) (fileState, bool, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return last, false, err
+	}
+	state := fileState{info.ModTime(), info.Size()}
+	return state, !state.modTime.Equal(last.modTime) || state.size != last.size, nil
+}
+
+// watchInput polls the input file and re-plays it every time it changes,
+// until ctx is canceled. Read and parse errors, e.g. while the file is being
+// written, are logged once and retried when the file changes again.
+func watchInput(ctx context.Context, opts playOptions) error {
+	var last fileState
+	statFailed := false
+	ticker := time.NewTicker(opts.watchEvery)
+	defer ticker.Stop()
+	for {
+		state, changed, err := fileChanged(opts.filename, last)
+		if err != nil {
+			if !statFailed {
+				slog.Warn("cannot stat input, retrying", "file", opts.filename, "err", err)
+			}
+			statFailed = true
+		} else if changed {
+			statFailed = false
+			last = state
+			var status exitStatus
+			if err := playWatched(ctx, opts); errors.As(err, &status) {
+				// an interrupted game ends the watch
+				return err
+			} else if err != nil {
+				slog.Warn("cannot play input, waiting for it to change", "file", opts.filename, "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func playWatched(ctx context.Context, opts playOptions) (err error) {
+	defer recoverFatal(&err)
+	return playInput(ctx, opts)
+}
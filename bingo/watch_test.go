@@ -0,0 +1,75 @@
+package bingo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileChanged(t *testing.T) {
+	input := writeFile(t, "input", sampleInput)
+	state, changed, err := fileChanged(input, fileState{})
+	if err != nil || !changed || state.size != int64(len(sampleInput)) {
+		t.Fatalf("first poll: %+v, changed %t, err %v, want size %d, true, nil", state, changed, err, len(sampleInput))
+	}
+	if _, changed, err := fileChanged(input, state); err != nil || changed {
+		t.Errorf("unchanged file: changed %t, err %v, want false, nil", changed, err)
+	}
+
+	later := state.modTime.Add(time.Second)
+	if err := os.Chtimes(input, later, later); err != nil {
+		t.Fatal(err)
+	}
+	touched, changed, err := fileChanged(input, state)
+	if err != nil || !changed || !touched.modTime.Equal(later) {
+		t.Errorf("touched file: %+v, changed %t, err %v, want %v, true, nil", touched, changed, err, later)
+	}
+
+	// a rewrite within the same modification time still changes the size
+	if err := os.WriteFile(input, []byte(sampleInput+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(input, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, err := fileChanged(input, touched); err != nil || !changed {
+		t.Errorf("resized file: changed %t, err %v, want true, nil", changed, err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	if last, changed, err := fileChanged(missing, state); err == nil || changed || last != state {
+		t.Errorf("missing file: %+v, changed %t, err %v, want %+v, false and an error", last, changed, err, state)
+	}
+}
+
+func TestWatchInputWarnsOnce(t *testing.T) {
+	t.Cleanup(saveGlobals())
+	input := writeFile(t, "input", "1,2,3\n\n1 2\n")
+	opts, err := parsePlayArgs([]string{"-watch-file", "-watch-interval", "5ms", input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	logged := captureLog(slog.LevelWarn, func() {
+		if err := watchInput(ctx, opts); err != nil {
+			t.Errorf("watchInput() = %v", err)
+		}
+	})
+	// the broken input is played once, not on every poll
+	if n := strings.Count(logged, "cannot play input"); n != 1 {
+		t.Errorf("logged %d play failures, want 1:\n%s", n, logged)
+	}
+
+	os.Remove(input)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	logged = captureLog(slog.LevelWarn, func() { watchInput(ctx, opts) })
+	if n := strings.Count(logged, "cannot stat input"); n != 1 {
+		t.Errorf("logged %d stat failures, want 1:\n%s", n, logged)
+	}
+}
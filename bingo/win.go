@@ -0,0 +1,225 @@
+package bingo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// line is a win pattern: the row,col coordinates of cells that all need to be
+// marked to complete it
+type line [][2]int
+
+// WinOptions selects the line patterns that count towards a win, and how many
+// of them have to be completed
+type WinOptions struct {
+	Rows      bool
+	Columns   bool
+	Diagonals bool // main and anti-diagonal, square boards only
+	Corners   bool // the four corner cells as a single pattern
+	Lines     int  // completed patterns needed to win
+}
+
+// defaultWinOptions are the standard rules: any full row or column wins
+var defaultWinOptions = WinOptions{Rows: true, Columns: true, Lines: 1}
+
+// winOptions are the rules used by boardWon
+var winOptions = defaultWinOptions
+
+func rowLines(rows, cols int) (lines []line) {
+	for y := 0; y < rows; y++ {
+		var l line
+		for x := 0; x < cols; x++ {
+			l = append(l, [2]int{y, x})
+		}
+		lines = append(lines, l)
+	}
+	return
+}
+
+func columnLines(rows, cols int) (lines []line) {
+	for x := 0; x < cols; x++ {
+		var l line
+		for y := 0; y < rows; y++ {
+			l = append(l, [2]int{y, x})
+		}
+		lines = append(lines, l)
+	}
+	return
+}
+
+// diagonalLines returns the main and anti-diagonal, or nothing for boards
+// that are not square
+func diagonalLines(rows, cols int) []line {
+	if rows != cols {
+		return nil
+	}
+	var main, anti line
+	for i := 0; i < rows; i++ {
+		main = append(main, [2]int{i, i})
+		anti = append(anti, [2]int{i, cols - 1 - i})
+	}
+	return []line{main, anti}
+}
+
+// cornerLines returns the four corner cells as one pattern. Any rectangular
+// board has four corners; on a single row or column some of them coincide and
+// are only listed once.
+func cornerLines(rows, cols int) []line {
+	var corners line
+	seen := map[[2]int]bool{}
+	for _, cell := range [][2]int{{0, 0}, {0, cols - 1}, {rows - 1, 0}, {rows - 1, cols - 1}} {
+		if !seen[cell] {
+			seen[cell] = true
+			corners = append(corners, cell)
+		}
+	}
+	return []line{corners}
+}
+
+// buildLines returns every win pattern enabled by opts for a board of the
+// given size
+func buildLines(rows, cols int, opts WinOptions) (lines []line) {
+	if opts.Rows {
+		lines = append(lines, rowLines(rows, cols)...)
+	}
+	if opts.Columns {
+		lines = append(lines, columnLines(rows, cols)...)
+	}
+	if opts.Diagonals {
+		lines = append(lines, diagonalLines(rows, cols)...)
+	}
+	if opts.Corners {
+		lines = append(lines, cornerLines(rows, cols)...)
+	}
+	return
+}
+
+func lineMarked(b board, l line) bool {
+	for _, cell := range l {
+		if !b.marked[cell[0]][cell[1]] {
+			return false
+		}
+	}
+	return true
+}
+
+// describeLine names a win pattern after its shape: "row N", "column N",
+// "diagonal", "anti-diagonal" or, for anything else, "corners"
+func describeLine(l line) string {
+	sameRow, sameCol, diagonal, anti := true, true, true, true
+	for _, cell := range l {
+		sameRow = sameRow && cell[0] == l[0][0]
+		sameCol = sameCol && cell[1] == l[0][1]
+		diagonal = diagonal && cell[0] == cell[1]
+		anti = anti && cell[0]+cell[1] == l[0][0]+l[0][1]
+	}
+	switch {
+	case sameRow:
+		return fmt.Sprintf("row %d", l[0][0])
+	case sameCol:
+		return fmt.Sprintf("column %d", l[0][1])
+	case diagonal:
+		return "diagonal"
+	case anti:
+		return "anti-diagonal"
+	}
+	return "corners"
+}
+
+// preCompletedLine reports whether some win pattern of the board is already
+// fully marked before any draw, which only wildcards can cause, and names it
+func preCompletedLine(b board) (bool, string) {
+	for _, l := range winLines(b.dimensions()) {
+		if lineMarked(b, l) {
+			return true, describeLine(l)
+		}
+	}
+	return false, ""
+}
+
+// narrateWin describes the pattern the board completed on the draw at
+// drawIndex, cell by cell with the draw that marked each one. Free cells are
+// listed as "free". It returns false if no pattern completed on that draw.
+func narrateWin(b board, numbers []int, drawIndex int) (string, bool) {
+	indices := drawIndices(numbers[:drawIndex+1])
+	for _, l := range winLines(b.dimensions()) {
+		if completed, ok := lineDraw(b, l, indices); !ok || completed != drawIndex {
+			continue
+		}
+		cells := make([]string, len(l))
+		draws := make([]string, len(l))
+		for i, cell := range l {
+			cells[i] = fmt.Sprint(b.numbers[cell[0]][cell[1]])
+			if b.isFree(cell[0], cell[1]) {
+				draws[i] = "free"
+			} else {
+				draws[i] = fmt.Sprint(indices[b.numbers[cell[0]][cell[1]]] + 1)
+			}
+		}
+		return fmt.Sprintf("board %d won on draw #%02d (number %d): %s completed with cells %s marked on draws %s",
+			b.index, drawIndex+1, numbers[drawIndex], describeLine(l),
+			strings.Join(cells, ","), strings.Join(draws, ",")), true
+	}
+	return "", false
+}
+
+// lineCache memoizes winLines per board size and win options
+var lineCache sync.Map // lineCacheKey -> []line
+
+// lastLines short-circuits lineCache for the common case of every board
+// having the same size
+var lastLines atomic.Pointer[cachedLines]
+
+type lineCacheKey struct {
+	dims dimensions
+	opts WinOptions
+}
+
+type cachedLines struct {
+	key   lineCacheKey
+	lines []line
+}
+
+// winLines returns the win patterns for a board of the given size under the
+// current winOptions. They are built once per size and shared, so callers
+// must not modify them.
+func winLines(dims dimensions) []line {
+	return winLinesFor(dims, winOptions)
+}
+
+// winLinesFor is winLines under the given win options
+func winLinesFor(dims dimensions, opts WinOptions) []line {
+	key := lineCacheKey{dims, opts}
+	if last := lastLines.Load(); last != nil && last.key == key {
+		return last.lines
+	}
+	lines, _ := lineCache.LoadOrStore(key, buildLines(dims.rows, dims.cols, opts))
+	lastLines.Store(&cachedLines{key, lines.([]line)})
+	return lines.([]line)
+}
+
+// hasCompletedLines reports whether at least n of the patterns are fully
+// marked on the board, stopping as soon as they are found
+func hasCompletedLines(b board, lines []line, n int) bool {
+	for _, l := range lines {
+		if lineMarked(b, l) {
+			n--
+			if n <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// completedLines counts the patterns that are fully marked on the board
+func completedLines(b board, lines []line) (completed int) {
+	for _, l := range lines {
+		if lineMarked(b, l) {
+			completed++
+		}
+	}
+	return
+}
@@ -0,0 +1,156 @@
+package bingo
+
+import (
+	"slices"
+	"testing"
+)
+
+// useWinOptions switches the win rules for the rest of the test
+func useWinOptions(t *testing.T, opts WinOptions) {
+	t.Helper()
+	saved := winOptions
+	winOptions = opts
+	t.Cleanup(func() { winOptions = saved })
+}
+
+func TestWinPatternCombinations(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n4 5 6\n7 8 9\n")
+	tests := []struct {
+		name   string
+		opts   WinOptions
+		marked []int
+		want   bool
+	}{
+		{"corners only", WinOptions{Corners: true, Lines: 1}, []int{1, 3, 7, 9}, true},
+		{"corners only, row", WinOptions{Corners: true, Lines: 1}, []int{1, 2, 3}, false},
+		{"rows and diagonals, diagonal", WinOptions{Rows: true, Diagonals: true, Lines: 1}, []int{1, 5, 9}, true},
+		{"rows and diagonals, anti-diagonal", WinOptions{Rows: true, Diagonals: true, Lines: 1}, []int{3, 5, 7}, true},
+		{"rows and diagonals, row", WinOptions{Rows: true, Diagonals: true, Lines: 1}, []int{4, 5, 6}, true},
+		{"rows and diagonals, column", WinOptions{Rows: true, Diagonals: true, Lines: 1}, []int{1, 4, 7}, false},
+		{"two lines, row and column", WinOptions{Rows: true, Columns: true, Lines: 2}, []int{1, 2, 3, 4, 7}, true},
+		{"two lines, one row", WinOptions{Rows: true, Columns: true, Lines: 2}, []int{1, 2, 3, 4}, false},
+		{"two lines, diagonal and corners", WinOptions{Diagonals: true, Corners: true, Lines: 2}, []int{1, 3, 5, 7, 9}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useWinOptions(t, tt.opts)
+			if got := boardWon(mark(b, tt.marked...)); got != tt.want {
+				t.Errorf("boardWon() with %v marked = %t, want %t", tt.marked, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCornersWin(t *testing.T) {
+	b := parseBoard(t, " 1  2  3  4\n 5  6  7  8\n 9 10 11 12\n")
+	cornersMarked := mark(b, 1, 4, 9, 12)
+	if boardWon(cornersMarked) {
+		t.Error("corners won without -corners")
+	}
+	useWinOptions(t, WinOptions{Rows: true, Columns: true, Corners: true, Lines: 1})
+	if !boardWon(cornersMarked) {
+		t.Error("3x4 board with its four corners marked did not win")
+	}
+	if boardWon(mark(b, 1, 4, 9)) {
+		t.Error("won with three corners marked")
+	}
+	if row := parseBoard(t, "1 2 3\n"); !boardWon(mark(row, 1, 3)) {
+		t.Error("single row board with both ends marked did not win")
+	}
+
+	// (2+3+5+6+7+8+10+11) * 12
+	input := writeFile(t, "input", "dim 3x4\n1,4,9,12\n\n"+grid(3, 4, 1))
+	equalLines(t, playLines(t, "-corners", input), []string{"part1 result: 624", "part2 result: 624"})
+}
+
+func TestBuildLines(t *testing.T) {
+	lines := buildLines(5, 5, WinOptions{Rows: true, Columns: true, Diagonals: true, Lines: 1})
+	if len(lines) != 12 {
+		t.Fatalf("%d lines, want 5 rows, 5 columns and 2 diagonals", len(lines))
+	}
+	var names []string
+	for _, l := range lines {
+		if len(l) != 5 {
+			t.Errorf("%s has %d cells, want 5", describeLine(l), len(l))
+		}
+		names = append(names, describeLine(l))
+	}
+	want := []string{"row 0", "row 1", "row 2", "row 3", "row 4",
+		"column 0", "column 1", "column 2", "column 3", "column 4", "diagonal", "anti-diagonal"}
+	if !slices.Equal(names, want) {
+		t.Errorf("lines %v, want %v", names, want)
+	}
+	if got, want := lines[11], (line{{0, 4}, {1, 3}, {2, 2}, {3, 1}, {4, 0}}); !slices.Equal(got, want) {
+		t.Errorf("anti-diagonal = %v, want %v", got, want)
+	}
+
+	if lines := buildLines(3, 4, WinOptions{Diagonals: true, Lines: 1}); len(lines) != 0 {
+		t.Errorf("non-square board has diagonals %v", lines)
+	}
+	if got, want := buildLines(3, 4, WinOptions{Corners: true, Lines: 1}), []line{{{0, 0}, {0, 3}, {2, 0}, {2, 3}}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("corners = %v, want %v", got, want)
+	}
+}
+
+func TestPreCompletedLine(t *testing.T) {
+	b := parseBoard(t, "1 2 3\n* 5 *\n7 8 9\n")
+	if ok, name := preCompletedLine(b); ok {
+		t.Errorf("preCompletedLine() = %s before the center is free", name)
+	}
+	if err := setFreeCenter(&b); err != nil {
+		t.Fatal(err)
+	}
+	if ok, name := preCompletedLine(b); !ok || name != "row 1" {
+		t.Errorf("preCompletedLine() = %t, %q, want true, \"row 1\"", ok, name)
+	}
+
+	useWinOptions(t, WinOptions{Rows: true, Columns: true, Diagonals: true, Lines: 1})
+	if ok, name := preCompletedLine(parseBoard(t, "1 * 3\n4 * 6\n7 * 9\n")); !ok || name != "column 1" {
+		t.Errorf("preCompletedLine() = %t, %q, want true, \"column 1\"", ok, name)
+	}
+	if ok, name := preCompletedLine(parseBoard(t, "1 2 *\n4 * 6\n* 8 9\n")); !ok || name != "anti-diagonal" {
+		t.Errorf("preCompletedLine() = %t, %q, want true, \"anti-diagonal\"", ok, name)
+	}
+}
+
+func TestNarrateWin(t *testing.T) {
+	equalLines(t, playLines(t, "-explain-win", writeFile(t, "input", sampleInput)), []string{
+		"part1 result: 4512",
+		"part1 board 2 won on draw #12 (number 24): row 0 completed with cells 14,21,17,24,4 marked on draws 10,11,6,12,2",
+		"part2 result: 1924",
+		"part2 board 1 won on draw #15 (number 13): column 2 completed with cells 0,13,7,10,16 marked on draws 9,15,1,13,14",
+	})
+
+	b := mark(parseBoard(t, "1 * 3\n4 5 6\n"), 3, 1)
+	narration, ok := narrateWin(b, []int{3, 9, 1}, 2)
+	if want := "board 0 won on draw #03 (number 1): row 0 completed with cells 1,0,3 marked on draws 3,free,1"; !ok || narration != want {
+		t.Errorf("narrateWin() = %q, %t, want %q", narration, ok, want)
+	}
+	if narration, ok := narrateWin(b, []int{3, 9, 1}, 1); ok {
+		t.Errorf("narrateWin() on a draw completing nothing = %q", narration)
+	}
+}
+
+func TestDiagonalOnlyWin(t *testing.T) {
+	// board 0 completes only its diagonal 1,5,9; board 1 its row 0 with 11
+	input := "dim 3x3\n1,5,9,10,11\n\n1 2 3\n4 5 6\n7 8 9\n\n10 5 11\n12 1 13\n9 14 15\n"
+	numbers, boards := parseInput(t, input)
+
+	useWinOptions(t, WinOptions{Rows: true, Columns: true, Diagonals: true, Lines: 1})
+	part1 := playBingoBestChoice(cloneBoards(boards), numbers)
+	if part1.Board.index != 0 || part1.Draw != 2 || part1.Sum != 2+3+4+6+7+8 || part1.Score != 30*9 {
+		t.Errorf("part 1 = board %d, draw %d, sum %d, score %d, want board 0, 2, 30, 270", part1.Board.index, part1.Draw, part1.Sum, part1.Score)
+	}
+	if got := calcBoardScore(part1.Board); got != 30 {
+		t.Errorf("calcBoardScore() of the diagonal winner = %d, want 30", got)
+	}
+	part2 := playBingoWorstChoice(cloneBoards(boards), numbers)
+	if part2.Board.index != 1 || part2.Draw != 4 || part2.Score != (12+13+14+15)*11 {
+		t.Errorf("part 2 = board %d, draw %d, score %d, want board 1, 4, 594", part2.Board.index, part2.Draw, part2.Score)
+	}
+
+	file := writeFile(t, "input", input)
+	equalLines(t, playLines(t, "-diagonals", file), []string{"part1 result: 270", "part2 result: 594"})
+	// without diagonals board 0 never wins
+	equalLines(t, playLines(t, file), []string{"part1 result: 594", "part2 result: no winning board"})
+}